@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/mysll/toolkit"
 	log "github.com/sirupsen/logrus"
+	"mediahub/internal/media"
 	"os"
 	"path/filepath"
 )
@@ -31,10 +32,45 @@ type Cors struct {
 	AllowHeaders []string `json:"allow_headers" env:"ALLOW_HEADERS"`
 }
 
+type Media struct {
+	ReleaseGroups []string                `json:"release_groups" env:"RELEASE_GROUPS"` // 自定义发布组，正则
+	ParseSteps    []string                `json:"parse_steps" env:"PARSE_STEPS"`       // 解析 Step 顺序，为空使用默认顺序，未列出的 Step 不参与解析
+	CustomSteps   []media.RegexStepConfig `json:"custom_steps"`                        // 自定义正则 Step
+	QualityScore  media.QualityScore      `json:"quality_score"`                       // 质量评分，覆盖默认分值
+	Providers     []string                `json:"providers" env:"PROVIDERS"`           // 元数据来源优先级，tmdb、douban、tvdb，为空使用默认顺序
+	CategoryFile  string                  `json:"category_file" env:"CATEGORY_FILE"`   // 二级分类规则文件，yaml 或 json，相对路径基于数据目录，为空使用默认规则
+}
+
+type Tmdb struct {
+	ApiKey    string  `json:"api_key" env:"API_KEY"`
+	Language  string  `json:"language" env:"LANGUAGE"`
+	Proxy     string  `json:"proxy" env:"PROXY"`
+	SearchTTL int     `json:"search_ttl" env:"SEARCH_TTL"` // 搜索结果缓存有效期，小时
+	DetailTTL int     `json:"detail_ttl" env:"DETAIL_TTL"` // 详情缓存有效期，小时
+	Offline   bool    `json:"offline" env:"OFFLINE"`       // 离线模式，只使用缓存
+	RateLimit float64 `json:"rate_limit" env:"RATE_LIMIT"` // 每秒请求数，0 不限流
+	RateBurst int     `json:"rate_burst" env:"RATE_BURST"` // 突发请求数
+}
+
+type Douban struct {
+	Enable bool   `json:"enable" env:"ENABLE"`
+	Proxy  string `json:"proxy" env:"PROXY"`
+}
+
+type Tvdb struct {
+	ApiKey string `json:"api_key" env:"API_KEY"`
+	Pin    string `json:"pin" env:"PIN"` // 订阅用户的 PIN，可为空
+	Proxy  string `json:"proxy" env:"PROXY"`
+}
+
 type Config struct {
 	App      App      `json:"app"`
 	Database Database `json:"database"`
 	Cors     Cors     `json:"cors" envPrefix:"CORS_"`
+	Media    Media    `json:"media" envPrefix:"MEDIA_"`
+	Tmdb     Tmdb     `json:"tmdb" envPrefix:"TMDB_"`
+	Douban   Douban   `json:"douban" envPrefix:"DOUBAN_"`
+	Tvdb     Tvdb     `json:"tvdb" envPrefix:"TVDB_"`
 }
 
 func (c *Config) Load(f string) {
@@ -71,6 +107,13 @@ func DefaultConfig() *Config {
 			AllowMethods: []string{"*"},
 			AllowHeaders: []string{"*"},
 		},
+		Tmdb: Tmdb{
+			Language:  "zh",
+			SearchTTL: 24,
+			DetailTTL: 24 * 7,
+			RateLimit: 20,
+			RateBurst: 10,
+		},
 	}
 	return config
 }
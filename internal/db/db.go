@@ -10,10 +10,13 @@ var db *gorm.DB
 
 func InitDb(d *gorm.DB) {
 	db = d
-	err := db.AutoMigrate(new(model.User))
+	err := db.AutoMigrate(new(model.User), new(model.CustomWord), new(model.TmdbCache))
 	if err != nil {
 		log.Fatalf("init db failed, error %s", err.Error())
 	}
+	if err = ReloadWords(); err != nil {
+		log.Errorf("load custom words failed, error %s", err.Error())
+	}
 }
 
 func GetDb() *gorm.DB {
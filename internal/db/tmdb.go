@@ -0,0 +1,51 @@
+package db
+
+import (
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"mediahub/internal/model"
+	"time"
+)
+
+func GetTmdbCache(key string) (*model.TmdbCache, error) {
+	cache := new(model.TmdbCache)
+	if err := db.Where("cache_key = ?", key).First(cache).Error; err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func SaveTmdbCache(cache *model.TmdbCache) error {
+	return db.Save(cache).Error
+}
+
+// DeleteTmdbCache 删除 key 以 prefix 开头的缓存，prefix 为空时全部删除
+func DeleteTmdbCache(prefix string) error {
+	if prefix == "" {
+		return db.Where("1 = 1").Delete(new(model.TmdbCache)).Error
+	}
+	return db.Where("substr(cache_key, 1, length(?)) = ?", prefix, prefix).Delete(new(model.TmdbCache)).Error
+}
+
+// TmdbStore 用数据库持久化 TMDB 缓存
+type TmdbStore struct{}
+
+func (TmdbStore) Load(key string) ([]byte, time.Time, bool) {
+	cache, err := GetTmdbCache(key)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Errorf("load tmdb cache %s failed, %s", key, err.Error())
+		}
+		return nil, time.Time{}, false
+	}
+	return cache.Data, cache.ExpiresAt, true
+}
+
+func (TmdbStore) Save(key string, data []byte, expiresAt time.Time) error {
+	return SaveTmdbCache(&model.TmdbCache{Key: key, Data: data, ExpiresAt: expiresAt})
+}
+
+func (TmdbStore) Delete(prefix string) error {
+	return DeleteTmdbCache(prefix)
+}
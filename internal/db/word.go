@@ -0,0 +1,52 @@
+package db
+
+import (
+	log "github.com/sirupsen/logrus"
+	"mediahub/internal/model"
+	"mediahub/internal/utils"
+)
+
+func GetWords() ([]model.CustomWord, error) {
+	var words []model.CustomWord
+	err := db.Order("id").Find(&words).Error
+	return words, err
+}
+
+func GetWord(id uint) (*model.CustomWord, error) {
+	word := new(model.CustomWord)
+	if err := db.First(word, id).Error; err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+func SaveWord(word *model.CustomWord) error {
+	return db.Save(word).Error
+}
+
+func DeleteWord(id uint) error {
+	return db.Delete(new(model.CustomWord), id).Error
+}
+
+// ReloadWords 从数据库重新加载启用的识别词
+func ReloadWords() error {
+	words, err := GetWords()
+	if err != nil {
+		return err
+	}
+	rules := make([]*utils.Word, 0, len(words))
+	for _, w := range words {
+		if w.Disabled {
+			continue
+		}
+		rule, err := utils.ParseWord(w.Word, w.Regex)
+		if err != nil {
+			log.Warnf("parse word %d(%s) failed, %s", w.ID, w.Word, err.Error())
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	utils.LoadWords(rules)
+	log.Infof("load %d custom words", len(rules))
+	return nil
+}
@@ -0,0 +1,402 @@
+package media
+
+import (
+	"fmt"
+	"mediahub/internal/utils"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	AnimBeginRe        = regexp.MustCompile(`^\s*[\[【]`)
+	AnimBracketRe      = regexp.MustCompile(`\[[^\[\]]*]|\([^()]*\)`)
+	AnimStarRe         = regexp.MustCompile(`★[^★]*★`)
+	AnimEpisodeRe      = regexp.MustCompile(`(?i)^(?:第|EP?|#)?\s*(\d{1,4})(?:\.5)?\s*(?:v\d)?\s*[话話集]?\s*(?:END|FIN|完)?$`)
+	AnimEpisodeRangeRe = regexp.MustCompile(`(?i)^(?:第|EP?|TV)?\s*(\d{1,4})\s*[-~～]\s*(?:EP?)?(\d{1,4})\s*[话話集]?\s*(?:END|FIN|完|合集|全集)?$`)
+	AnimDashEpisodeRe  = regexp.MustCompile(`(?i)\s+-\s+(?:EP?)?(\d{1,4})(?:v\d)?(?:\s*-\s*(\d{1,4})(?:v\d)?)?(?:\s*END)?(?:\s+|$)`)
+	AnimSxxExxRe       = regexp.MustCompile(`(?i)^(.+?)\s+S(\d{1,2})E(\d{1,4})$`)
+	AnimTailEpisodeRe  = regexp.MustCompile(`(?i)^(.+?)\s+(?:EP?|第|#)?(\d{2,4})(?:v\d)?[话話集]?$`)
+	AnimSpecialRe      = regexp.MustCompile(`(?i)^(SPs?|OVA|OAD|Specials?|特别篇|番外篇?)\s*(\d{1,3})?(?:v\d)?$`)
+	AnimDashSpecialRe  = regexp.MustCompile(`(?i)\s+-\s+(SPs?|OVA|OAD|Specials?)\s*(\d{1,3})?(?:v\d)?(?:\s+|$)`)
+	AnimTailSpecialRe  = regexp.MustCompile(`(?i)^(.+?)\s+(SPs?|OVA|OAD|Specials|特别篇|番外篇?)\s*(\d{1,3})?$`)
+	AnimSeasonRe       = regexp.MustCompile(`(?i)\s+(?:S(\d{1,2})|Season\s*(\d{1,2})|(\d{1,2})(?:st|nd|rd|th)\s+Season|第([0-9一二三四五六七八九十]+)[季期])$`)
+	AnimMovieRe        = regexp.MustCompile(`(?i)剧场版|劇場版|\bMovie\b|Gekijouban`)
+	AnimSubtitleTagRe  = regexp.MustCompile(`(?i)[\[【(](?:[^]】)]*[\s_&])?(?:CHS|CHT|GB|BIG5|简体|繁体|简繁|繁简|简日|繁日|简中|繁中)(?:[\s_&][^]】)]*)?[]】)]`)
+	AnimBracketEpRe    = regexp.MustCompile(`(?i)[\[【](?:第|EP?)?\d{1,4}(?:\s*[-~]\s*\d{1,4})?(?:v\d)?[话話集]?(?:\s*(?:END|FIN))?[]】]`)
+	AnimSceneRe        = regexp.MustCompile(`(?i)S\d{1,2}E\d{1,4}|[.\s](?:19|20)\d{2}[.\s]`)
+	AnimWordSplitRe    = regexp.MustCompile(`[\s_+&\-]+`)
+	AnimNameSplitRe    = regexp.MustCompile(`\s*[/|｜]\s*`)
+	AnimCnSubtitleRe   = regexp.MustCompile(`^[简繁體体中日英双雙语語内內封嵌外挂掛字幕版]+$`)
+	AnimWebDLRe        = regexp.MustCompile(`(?i)WEB-DL`)
+	AnimNoiseRe        = regexp.MustCompile(`(?i)^(?:MP4|MKV|AVI|V\d|Baha|CR|B-?Global|Bilibili|ABEMA|NF|AMZN|ADN|Hi10P?|Ma10p|8bit|10bit|BIG5_MP4|GB_MP4|招募.*|新番|月新番|国漫|日漫|番剧)$`)
+)
+
+// IsAnim 根据方括号布局、字幕组等判断是否为动漫发布名
+func IsAnim(title string) bool {
+	title = strings.TrimSpace(title)
+	if !AnimBeginRe.MatchString(title) {
+		return false
+	}
+	title = strings.NewReplacer("【", "[", "】", "]").Replace(title)
+	if group := AnimBracketRe.FindString(title); group != "" && IsAnimGroup(group[1:len(group)-1]) {
+		return true
+	}
+	if AnimSceneRe.MatchString(title) {
+		return false
+	}
+	if AnimBracketEpRe.MatchString(title) || AnimDashEpisodeRe.MatchString(title) {
+		return true
+	}
+	return AnimSubtitleTagRe.MatchString(title)
+}
+
+type MetaAnim struct {
+	*Meta
+}
+
+func NewMetaAnim(title, subtitle string, isFile bool) *MetaAnim {
+	if title == "" {
+		return nil
+	}
+	self := &MetaAnim{
+		Meta: &Meta{OrgTitle: title, OrgString: title, Subtitle: subtitle, IsFile: isFile},
+	}
+	self.parseTitle(title)
+	return self
+}
+
+type animSegment struct {
+	text    string
+	bracket bool
+}
+
+func splitAnimSegments(title string) []animSegment {
+	segments := make([]animSegment, 0, 8)
+	last := 0
+	for _, loc := range AnimBracketRe.FindAllStringIndex(title, -1) {
+		if text := strings.TrimSpace(title[last:loc[0]]); text != "" {
+			segments = append(segments, animSegment{text: text})
+		}
+		if text := strings.TrimSpace(title[loc[0]+1 : loc[1]-1]); text != "" {
+			segments = append(segments, animSegment{text: text, bracket: true})
+		}
+		last = loc[1]
+	}
+	if text := strings.TrimSpace(title[last:]); text != "" {
+		segments = append(segments, animSegment{text: text})
+	}
+	return segments
+}
+
+func (m *MetaAnim) parseTitle(title string) {
+	if IsMediaFile(title) {
+		title = strings.TrimSuffix(title, path.Ext(title))
+	}
+	title = AnimStarRe.ReplaceAllString(title, " ")
+	title = strings.NewReplacer("【", "[", "】", "]").Replace(title)
+	segments := splitAnimSegments(title)
+
+	var names []string
+	nameFromText := false
+	for i, seg := range segments {
+		if !seg.bracket {
+			if name := m.parseText(seg.text); name != "" && !nameFromText {
+				names = []string{name}
+				nameFromText = true
+			}
+			continue
+		}
+		if m.parseEpisode(seg.text) || m.parseTags(seg.text) {
+			continue
+		}
+		// 第一个无法识别的括号为字幕组
+		if i == 0 && m.ReleaseGroup == "" {
+			m.ReleaseGroup = seg.text
+			continue
+		}
+		if !nameFromText && m.BeginEpisode == 0 {
+			names = append(names, seg.text)
+		}
+	}
+	// 只有一个括号内容时，字幕组其实是标题
+	if len(names) == 0 && m.ReleaseGroup != "" && !IsAnimGroup(m.ReleaseGroup) {
+		names = append(names, m.ReleaseGroup)
+		m.ReleaseGroup = ""
+	}
+	for _, name := range names {
+		m.parseName(name)
+	}
+
+	if m.Subtitle != "" {
+		m.parseTags(m.Subtitle)
+	}
+	if m.BeginEpisode == 0 && AnimMovieRe.MatchString(title) {
+		m.MediaType = MediaTypeMovie
+	} else {
+		m.MediaType = MediaTypeTv
+	}
+}
+
+// parseText 解析括号外的文本，返回其中的标题部分
+func (m *MetaAnim) parseText(text string) string {
+	padded := fmt.Sprintf(" %s ", text)
+	if loc := AnimDashSpecialRe.FindStringSubmatchIndex(padded); loc != nil {
+		num := ""
+		if loc[4] >= 0 {
+			num = padded[loc[4]:loc[5]]
+		}
+		m.setSpecial(padded[loc[2]:loc[3]], num)
+		m.parseTags(padded[loc[1]:])
+		return m.trimTags(padded[:loc[0]])
+	}
+	if loc := AnimDashEpisodeRe.FindStringSubmatchIndex(padded); loc != nil {
+		begin, _ := strconv.Atoi(padded[loc[2]:loc[3]])
+		end := 0
+		if loc[4] >= 0 {
+			end, _ = strconv.Atoi(padded[loc[4]:loc[5]])
+		}
+		m.setEpisode(begin, end)
+		m.parseTags(padded[loc[1]:])
+		return m.trimTags(padded[:loc[0]])
+	}
+	name := m.trimTags(text)
+	if match := AnimSxxExxRe.FindStringSubmatch(name); match != nil {
+		season, _ := strconv.Atoi(match[2])
+		episode, _ := strconv.Atoi(match[3])
+		if m.BeginSeason == 0 {
+			m.BeginSeason = season
+			m.TotalSeasons = 1
+		}
+		m.setEpisode(episode, 0)
+		return strings.TrimSpace(match[1])
+	}
+	if match := AnimTailSpecialRe.FindStringSubmatch(name); match != nil {
+		m.setSpecial(match[2], match[3])
+		return strings.TrimSpace(match[1])
+	}
+	if match := AnimTailEpisodeRe.FindStringSubmatch(name); match != nil {
+		if episode, _ := strconv.Atoi(match[2]); !isYear(episode) {
+			m.setEpisode(episode, 0)
+			return strings.TrimSpace(match[1])
+		}
+	}
+	return name
+}
+
+// trimTags 去掉文本结尾的技术标签，返回剩余部分
+func (m *MetaAnim) trimTags(text string) string {
+	words := strings.Fields(text)
+	for len(words) > 0 && m.parseTag(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+func (m *MetaAnim) parseEpisode(text string) bool {
+	if match := AnimSpecialRe.FindStringSubmatch(text); match != nil {
+		m.setSpecial(match[1], match[2])
+		return true
+	}
+	if match := AnimEpisodeRangeRe.FindStringSubmatch(text); match != nil {
+		begin, _ := strconv.Atoi(match[1])
+		end, _ := strconv.Atoi(match[2])
+		m.setEpisode(begin, end)
+		return true
+	}
+	if match := AnimEpisodeRe.FindStringSubmatch(text); match != nil {
+		episode, _ := strconv.Atoi(match[1])
+		if isYear(episode) && DigitRe.MatchString(text) {
+			if m.Year == 0 {
+				m.Year = episode
+			}
+			return true
+		}
+		m.setEpisode(episode, 0)
+		return true
+	}
+	return false
+}
+
+func (m *MetaAnim) setEpisode(begin, end int) {
+	if m.BeginEpisode != 0 {
+		return
+	}
+	m.BeginEpisode = begin
+	m.TotalEpisodes = 1
+	if end > begin {
+		m.EndEpisode = end
+		m.TotalEpisodes = end - begin + 1
+	}
+}
+
+// setSpecial 标记为特别篇，num 为特别篇集数
+func (m *MetaAnim) setSpecial(typ, num string) {
+	m.IsSpecial = true
+	if m.SpecialType == "" {
+		m.SpecialType = SpecialType(typ)
+	}
+	if episode, err := strconv.Atoi(num); err == nil {
+		m.setEpisode(episode, 0)
+	}
+}
+
+// parseTags 解析括号内的技术标签，全部识别时返回 true
+func (m *MetaAnim) parseTags(text string) bool {
+	text = AnimWebDLRe.ReplaceAllString(text, "WEBDL")
+	all := true
+	for _, word := range AnimWordSplitRe.Split(text, -1) {
+		if word == "" {
+			continue
+		}
+		if !m.parseTag(word) {
+			all = false
+		}
+	}
+	return all
+}
+
+func (m *MetaAnim) parseTag(word string) bool {
+	up := strings.ToUpper(word)
+	if pix := ResourcesPixRe.FindStringSubmatch(word); pix != nil {
+		if m.ResourcePix == "" {
+			if pix[1] != "" {
+				m.ResourcePix = strings.ToLower(pix[1])
+			} else {
+				m.ResourcePix = fmt.Sprintf("%sp", pix[2])
+			}
+		}
+		return true
+	}
+	if pix := ResourcesPixRe2.FindString(word); pix != "" {
+		if m.ResourcePix == "" {
+			m.ResourcePix = strings.ToLower(pix)
+		}
+		return true
+	}
+	if up == "WEBDL" || up == "WEBRIP" || SourceRe.MatchString(word) {
+		if m.ResourceType == "" {
+			if up == "WEBDL" {
+				m.ResourceType = "WEB-DL"
+			} else {
+				m.ResourceType = word
+			}
+		}
+		return true
+	}
+	if VideoEncodeRe.MatchString(word) {
+		if m.VideoEncode == "" {
+			m.VideoEncode = up
+		}
+		return true
+	}
+	if AudioEncodeRe.MatchString(word) {
+		if m.AudioEncode == "" {
+			m.AudioEncode = word
+		}
+		return true
+	}
+	// 国语、粤语配音等音轨语言
+	if strings.ContainsAny(word, "国粤") || strings.Contains(word, "配音") {
+		if audio, subs, ok := ParseLanguageTag(word); ok {
+			m.AudioLanguages = AppendLanguages(m.AudioLanguages, audio...)
+			m.SubtitleLanguages = AppendLanguages(m.SubtitleLanguages, subs...)
+			return true
+		}
+	}
+	if langs := animSubtitleLanguages(word); len(langs) > 0 {
+		m.SubtitleLanguages = AppendLanguages(m.SubtitleLanguages, langs...)
+		return true
+	}
+	if size := ParseSize(word); size > 0 {
+		if m.Size == 0 {
+			m.Size = size
+		}
+		return true
+	}
+	return AnimNoiseRe.MatchString(word)
+}
+
+// animSubtitleLanguages 识别字幕语言标签
+func animSubtitleLanguages(word string) []string {
+	switch strings.ToUpper(word) {
+	case "CHS", "SC", "GB", "ZHS":
+		return []string{"zh-Hans"}
+	case "CHT", "TC", "BIG5", "ZHT":
+		return []string{"zh-Hant"}
+	case "JP", "JPN":
+		return []string{"ja"}
+	case "JPSC":
+		return []string{"ja", "zh-Hans"}
+	case "JPTC":
+		return []string{"ja", "zh-Hant"}
+	case "ENG":
+		return []string{"en"}
+	}
+	if !AnimCnSubtitleRe.MatchString(word) {
+		return nil
+	}
+	langs := make([]string, 0, 3)
+	if strings.Contains(word, "简") {
+		langs = append(langs, "zh-Hans")
+	}
+	if strings.Contains(word, "繁") {
+		langs = append(langs, "zh-Hant")
+	}
+	if len(langs) == 0 && strings.Contains(word, "中") {
+		langs = append(langs, "zh")
+	}
+	if strings.Contains(word, "日") {
+		langs = append(langs, "ja")
+	}
+	if strings.Contains(word, "英") {
+		langs = append(langs, "en")
+	}
+	if len(langs) == 0 {
+		// 内封、字幕 等无语言信息的标签
+		langs = append(langs, "zh")
+	}
+	return langs
+}
+
+// parseName 拆分中英文名并识别名称中的季
+func (m *MetaAnim) parseName(name string) {
+	for _, part := range AnimNameSplitRe.Split(name, -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if match := AnimSeasonRe.FindStringSubmatch(part); match != nil {
+			for _, se := range match[1:] {
+				if se == "" {
+					continue
+				}
+				if season := int(utils.CnToNumber(se, 0)); season > 0 && m.BeginSeason == 0 {
+					m.BeginSeason = season
+					m.TotalSeasons = 1
+				}
+			}
+			part = strings.TrimRight(part[:len(part)-len(match[0])], " -_")
+		}
+		if utils.IsJapanese(part) {
+			if m.JpName == "" {
+				m.JpName = part
+			}
+		} else if utils.IsKorean(part) {
+			if m.KrName == "" {
+				m.KrName = part
+			}
+		} else if utils.IsChinese(part) {
+			if m.CnName == "" {
+				m.CnName = part
+			}
+		} else if m.EnName == "" {
+			m.EnName = part
+		}
+	}
+}
+
+func isYear(n int) bool {
+	return n >= 1900 && n <= 2050
+}
@@ -0,0 +1,59 @@
+package media
+
+import "testing"
+
+func TestIsAnim(t *testing.T) {
+	tests := []struct {
+		title string
+		anim  bool
+	}{
+		{"[ANi] Frieren - 05 [1080P][Baha][WEB-DL][AAC AVC][CHT].mp4", true},
+		{"[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv", true},
+		{"[桜都字幕组][间谍过家家 第二季][第05话][简日双语][1080P]", true},
+		{"【喵萌奶茶屋】★10月新番★[葬送的芙莉莲 / Sousou no Frieren][05][1080p][简日双语]", true},
+		{"[GM-Team][国漫][斗罗大陆][Douluo Dalu][2019][250][AVC][GB][1080P]", true},
+		{"Inception.2010.1080p.BluRay.x264-GRP", false},
+		{"The.Long.Season.S01E02.2023.1080p.WEB-DL", false},
+		{"[Movie] Something 2019", false},
+	}
+	for _, tt := range tests {
+		if got := IsAnim(tt.title); got != tt.anim {
+			t.Errorf("IsAnim(%q) = %v, want %v", tt.title, got, tt.anim)
+		}
+	}
+}
+
+func TestParseAnim(t *testing.T) {
+	tests := []struct {
+		title       string
+		cnName      string
+		enName      string
+		season      int
+		begin       int
+		end         int
+		specialType string
+		group       string
+		pix         string
+	}{
+		{"[ANi] Frieren - 05 [1080P][Baha][WEB-DL][AAC AVC][CHT].mp4", "", "Frieren", 0, 5, 0, "", "ANi", "1080p"},
+		{"[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv", "", "One Piece", 0, 1071, 0, "", "SubsPlease", "1080p"},
+		{"[Lilith-Raws] 间谍过家家 / Spy x Family - 05 [Baha][WEB-DL][1080p][AVC AAC][CHT][MP4]", "间谍过家家", "Spy x Family", 0, 5, 0, "", "Lilith-Raws", "1080p"},
+		{"[桜都字幕组][间谍过家家 第二季][第05话][简日双语][1080P]", "间谍过家家", "", 2, 5, 0, "", "桜都字幕组", "1080p"},
+		{"[Nekomoe kissaten][Sousou no Frieren][01-12][1080p][JPSC]", "", "Sousou no Frieren", 0, 1, 12, "", "Nekomoe kissaten", "1080p"},
+		{"【喵萌奶茶屋】★10月新番★[葬送的芙莉莲 / Sousou no Frieren][05][1080p][简日双语]", "葬送的芙莉莲", "Sousou no Frieren", 0, 5, 0, "", "喵萌奶茶屋", "1080p"},
+		// 分类标签不是标题
+		{"[GM-Team][国漫][斗罗大陆][Douluo Dalu][2019][250][AVC][GB][1080P]", "斗罗大陆", "Douluo Dalu", 0, 250, 0, "", "GM-Team", "1080p"},
+		{"[Sakurato] Kimetsu no Yaiba S2 - 03v2 [1080p]", "", "Kimetsu no Yaiba", 2, 3, 0, "", "Sakurato", "1080p"},
+		{"[VCB-Studio] Shingeki no Kyojin [OVA][01][Ma10p_1080p][x265_flac]", "", "Shingeki no Kyojin", 0, 1, 0, "OVA", "VCB-Studio", "1080p"},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, IsMediaFile(tt.title)).GetMeta()
+		if m.MediaType != MediaTypeTv || m.CnName != tt.cnName || m.EnName != tt.enName || m.BeginSeason != tt.season ||
+			m.BeginEpisode != tt.begin || m.EndEpisode != tt.end || m.SpecialType != tt.specialType ||
+			m.ReleaseGroup != tt.group || m.ResourcePix != tt.pix {
+			t.Errorf("%q: got type %d cn %q en %q season %d episodes %d-%d special %q group %q pix %q", tt.title,
+				m.MediaType, m.CnName, m.EnName, m.BeginSeason, m.BeginEpisode, m.EndEpisode, m.SpecialType,
+				m.ReleaseGroup, m.ResourcePix)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package media
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParseItem 批量识别的一条输入
+type ParseItem struct {
+	Title     string
+	Subtitle  string
+	MediaType int
+	IsFile    bool
+}
+
+// ParseMany 使用 workers 个协程批量识别，结果与输入顺序一致，workers 小于 1 时使用 CPU 数
+func ParseMany(items []ParseItem, workers int) []MetaInfo {
+	results := make([]MetaInfo, len(items))
+	if len(items) == 0 {
+		return results
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+	jobs := make(chan int, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item := items[i]
+				results[i] = NewMeta(item.Title, item.Subtitle, item.MediaType, item.IsFile)
+			}
+		}()
+	}
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
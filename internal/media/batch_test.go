@@ -0,0 +1,60 @@
+package media
+
+import "testing"
+
+// benchTitles 覆盖电影、剧集、动漫、中文和文件名
+var benchTitles = []string{
+	"The.Long.Season.S01E02.2023.1080p.WEB-DL.H264.AAC-GRP",
+	"Inception.2010.1080p.BluRay.x264.DTS-HD.MA.5.1-GRP",
+	"流浪地球.2019.国英双语.1080p.BluRay.x264",
+	"[桜都字幕组][间谍过家家 第二季][第05话][简日双语][1080P]",
+	"[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv",
+	"[狂飙] 第01-39集 全39集 4K 国语中字",
+	"Breaking.Bad.S05E16.Felina.2160p.WEB-DL.DDP5.1.HDR.HEVC.mkv",
+	"Show.2023.01.14.1080p.HDTV.x265",
+}
+
+func TestParseMany(t *testing.T) {
+	items := make([]ParseItem, 0, len(benchTitles))
+	for _, title := range benchTitles {
+		items = append(items, ParseItem{Title: title, MediaType: MediaUnknown, IsFile: IsMediaFile(title)})
+	}
+	for _, workers := range []int{0, 1, 3, 100} {
+		results := ParseMany(items, workers)
+		if len(results) != len(items) {
+			t.Fatalf("ParseMany(%d) returned %d results", workers, len(results))
+		}
+		for i, item := range items {
+			want := NewMeta(item.Title, "", MediaUnknown, item.IsFile).GetMeta()
+			got := results[i].GetMeta()
+			if got.GetName() != want.GetName() || got.MediaType != want.MediaType || got.BeginEpisode != want.BeginEpisode {
+				t.Errorf("ParseMany(%d)[%d] = %q, want %q", workers, i, got.GetName(), want.GetName())
+			}
+		}
+	}
+	if results := ParseMany(nil, 4); len(results) != 0 {
+		t.Errorf("ParseMany(nil) returned %d results", len(results))
+	}
+}
+
+func BenchmarkNewMeta(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		title := benchTitles[i%len(benchTitles)]
+		NewMeta(title, "", MediaUnknown, false)
+	}
+}
+
+func BenchmarkParseMany(b *testing.B) {
+	items := make([]ParseItem, 0, len(benchTitles)*32)
+	for i := 0; i < 32; i++ {
+		for _, title := range benchTitles {
+			items = append(items, ParseItem{Title: title, MediaType: MediaUnknown})
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParseMany(items, 0)
+	}
+}
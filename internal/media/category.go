@@ -0,0 +1,190 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TMDB 类型 ID
+const (
+	GenreAnimation   = 16
+	GenreDocumentary = 99
+	GenreKids        = 10762
+	GenreReality     = 10764
+	GenreTalk        = 10767
+)
+
+var ErrInvalidCategory = errors.New("invalid category rule")
+
+// CategoryRule 二级分类规则，各条件同时满足才匹配，条件内任一值匹配即可，没有条件的规则匹配全部。
+// 语言为 ISO 639-1 代码，国家为 ISO 3166-1 代码
+//
+//	[{name: 日番, media_type: tv, genre_ids: [16], countries: [JP]}, {name: 未分类}]
+type CategoryRule struct {
+	Name      string   `json:"name" yaml:"name"`
+	MediaType string   `json:"media_type" yaml:"media_type"` // movie、tv，为空不限
+	GenreIds  []int    `json:"genre_ids" yaml:"genre_ids"`   // TMDB 类型 ID
+	Genres    []string `json:"genres" yaml:"genres"`         // 类型名称，用于没有类型 ID 的来源
+	Languages []string `json:"languages" yaml:"languages"`   // 原始语言
+	Countries []string `json:"countries" yaml:"countries"`   // 出品国家
+}
+
+// DefaultCategoryRules 默认分类，按顺序匹配
+func DefaultCategoryRules() []CategoryRule {
+	return []CategoryRule{
+		{Name: "纪录片", MediaType: "movie", GenreIds: []int{GenreDocumentary}},
+		{Name: "动画电影", MediaType: "movie", GenreIds: []int{GenreAnimation}},
+		{Name: "华语电影", MediaType: "movie", Languages: []string{"zh", "cn", "bo", "za"}},
+		{Name: "外语电影", MediaType: "movie"},
+		{Name: "国漫", MediaType: "tv", GenreIds: []int{GenreAnimation}, Countries: []string{"CN", "TW", "HK"}},
+		{Name: "日番", MediaType: "tv", GenreIds: []int{GenreAnimation}, Countries: []string{"JP"}},
+		{Name: "纪录片", MediaType: "tv", GenreIds: []int{GenreDocumentary}},
+		{Name: "儿童", MediaType: "tv", GenreIds: []int{GenreKids}},
+		{Name: "综艺", MediaType: "tv", GenreIds: []int{GenreReality, GenreTalk}},
+		{Name: "国产剧", MediaType: "tv", Countries: []string{"CN", "TW", "HK"}},
+		{Name: "欧美剧", MediaType: "tv", Countries: []string{"US", "FR", "GB", "DE", "ES", "IT", "NL", "PT", "RU", "UK", "CA", "AU", "SE", "DK", "NO"}},
+		{Name: "日韩剧", MediaType: "tv", Countries: []string{"JP", "KP", "KR", "TH", "IN", "SG"}},
+		{Name: "未分类", MediaType: "tv"},
+	}
+}
+
+// Match 规则是否匹配 meta
+func (r *CategoryRule) Match(m *Meta) bool {
+	switch strings.ToLower(r.MediaType) {
+	case "movie":
+		if m.MediaType != MediaTypeMovie {
+			return false
+		}
+	case "tv":
+		if m.MediaType != MediaTypeTv {
+			return false
+		}
+	}
+	if len(r.GenreIds) > 0 || len(r.Genres) > 0 {
+		if !containsInt(r.GenreIds, m.GenreIds) && !containsFold(r.Genres, m.Genres) {
+			return false
+		}
+	}
+	if len(r.Languages) > 0 && !containsFold(r.Languages, []string{m.OriginalLanguage}) {
+		return false
+	}
+	if len(r.Countries) > 0 && !containsFold(r.Countries, m.Countries) {
+		return false
+	}
+	return true
+}
+
+func (r *CategoryRule) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidCategory)
+	}
+	switch strings.ToLower(r.MediaType) {
+	case "", "movie", "tv":
+	default:
+		return fmt.Errorf("%w: %s unknown media type %s", ErrInvalidCategory, r.Name, r.MediaType)
+	}
+	return nil
+}
+
+func containsInt(values []int, targets []int) bool {
+	for _, v := range values {
+		for _, t := range targets {
+			if v == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, targets []string) bool {
+	for _, v := range values {
+		for _, t := range targets {
+			if t != "" && strings.EqualFold(v, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Classifier 二级分类，第一个匹配的规则生效
+type Classifier struct {
+	rules []CategoryRule
+}
+
+func NewClassifier(rules []CategoryRule) (*Classifier, error) {
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Classifier{rules: rules}, nil
+}
+
+// Classify 返回分类名称，没有匹配的规则或类型未识别时为空
+func (c *Classifier) Classify(m *Meta) string {
+	if m.MediaType == MediaTypeUnknown {
+		return ""
+	}
+	for i := range c.rules {
+		if c.rules[i].Match(m) {
+			return c.rules[i].Name
+		}
+	}
+	return ""
+}
+
+// LoadCategoryRules 读取分类规则文件，按扩展名解析 yaml 或 json
+func LoadCategoryRules(file string) ([]CategoryRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []CategoryRule
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &rules)
+	case ".json":
+		err = json.Unmarshal(data, &rules)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file %s", ErrInvalidCategory, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+var classifier = struct {
+	sync.RWMutex
+	c *Classifier
+}{c: &Classifier{rules: DefaultCategoryRules()}}
+
+// SetCategoryRules 替换全局分类规则，规则为空时恢复默认规则
+func SetCategoryRules(rules []CategoryRule) error {
+	if len(rules) == 0 {
+		rules = DefaultCategoryRules()
+	}
+	c, err := NewClassifier(rules)
+	if err != nil {
+		return err
+	}
+	classifier.Lock()
+	defer classifier.Unlock()
+	classifier.c = c
+	return nil
+}
+
+// Classify 使用全局规则分类
+func Classify(m *Meta) string {
+	classifier.RLock()
+	defer classifier.RUnlock()
+	return classifier.c.Classify(m)
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultCategoryRules(t *testing.T) {
+	tests := []struct {
+		meta Meta
+		want string
+	}{
+		{Meta{MediaType: MediaTypeMovie, OriginalLanguage: "zh"}, "华语电影"},
+		{Meta{MediaType: MediaTypeMovie, OriginalLanguage: "en"}, "外语电影"},
+		{Meta{MediaType: MediaTypeMovie, OriginalLanguage: "zh", GenreIds: []int{GenreAnimation}}, "动画电影"},
+		{Meta{MediaType: MediaTypeMovie, GenreIds: []int{18, GenreDocumentary}}, "纪录片"},
+		{Meta{MediaType: MediaTypeTv, GenreIds: []int{GenreAnimation}, Countries: []string{"JP"}}, "日番"},
+		{Meta{MediaType: MediaTypeTv, GenreIds: []int{GenreAnimation}, Countries: []string{"CN"}}, "国漫"},
+		{Meta{MediaType: MediaTypeTv, GenreIds: []int{GenreAnimation}, Countries: []string{"US"}}, "欧美剧"},
+		{Meta{MediaType: MediaTypeTv, GenreIds: []int{GenreTalk}, Countries: []string{"CN"}}, "综艺"},
+		{Meta{MediaType: MediaTypeTv, Countries: []string{"cn"}}, "国产剧"},
+		{Meta{MediaType: MediaTypeTv, Countries: []string{"KR"}}, "日韩剧"},
+		{Meta{MediaType: MediaTypeTv, Countries: []string{"BR"}}, "未分类"},
+		{Meta{MediaType: MediaTypeUnknown, OriginalLanguage: "zh"}, ""},
+	}
+	c, err := NewClassifier(DefaultCategoryRules())
+	if err != nil {
+		t.Fatalf("NewClassifier failed, %s", err.Error())
+	}
+	for i, tt := range tests {
+		if got := c.Classify(&tt.meta); got != tt.want {
+			t.Errorf("Classify #%d = %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule CategoryRule
+		meta Meta
+		want bool
+	}{
+		{CategoryRule{Name: "全部"}, Meta{MediaType: MediaTypeTv}, true},
+		{CategoryRule{Name: "电影", MediaType: "Movie"}, Meta{MediaType: MediaTypeMovie}, true},
+		{CategoryRule{Name: "电影", MediaType: "movie"}, Meta{MediaType: MediaTypeTv}, false},
+		// 没有类型 ID 的来源按类型名称匹配
+		{CategoryRule{Name: "动画", GenreIds: []int{GenreAnimation}, Genres: []string{"动画"}}, Meta{Genres: []string{"动画"}}, true},
+		{CategoryRule{Name: "动画", GenreIds: []int{GenreAnimation}}, Meta{Genres: []string{"动画"}}, false},
+		{CategoryRule{Name: "英语", Languages: []string{"EN"}}, Meta{OriginalLanguage: "en"}, true},
+		{CategoryRule{Name: "英语", Languages: []string{"en"}}, Meta{}, false},
+		{CategoryRule{Name: "日本动画", GenreIds: []int{GenreAnimation}, Countries: []string{"JP"}},
+			Meta{GenreIds: []int{GenreAnimation}, Countries: []string{"US", "JP"}}, true},
+		{CategoryRule{Name: "日本动画", GenreIds: []int{GenreAnimation}, Countries: []string{"JP"}},
+			Meta{GenreIds: []int{GenreAnimation}, Countries: []string{"US"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Match(&tt.meta); got != tt.want {
+			t.Errorf("%+v Match(%+v) = %v, want %v", tt.rule, tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestNewClassifier(t *testing.T) {
+	tests := [][]CategoryRule{
+		{{Name: " "}},
+		{{Name: "电影", MediaType: "film"}},
+		{{Name: "电影", MediaType: "movie"}, {Name: ""}},
+	}
+	for _, rules := range tests {
+		if _, err := NewClassifier(rules); !errors.Is(err, ErrInvalidCategory) {
+			t.Errorf("NewClassifier(%+v) error %v, want %v", rules, err, ErrInvalidCategory)
+		}
+	}
+	c, err := NewClassifier(nil)
+	if err != nil || c.Classify(&Meta{MediaType: MediaTypeMovie}) != "" {
+		t.Errorf("NewClassifier(nil) = %v", err)
+	}
+}
+
+func TestLoadCategoryRules(t *testing.T) {
+	want := []CategoryRule{
+		{Name: "日番", MediaType: "tv", GenreIds: []int{GenreAnimation}, Countries: []string{"JP"}},
+		{Name: "其他"},
+	}
+	files := map[string]string{
+		"rules.yaml": "- name: 日番\n  media_type: tv\n  genre_ids: [16]\n  countries: [JP]\n- name: 其他\n",
+		"rules.yml":  "- {name: 日番, media_type: tv, genre_ids: [16], countries: [JP]}\n- {name: 其他}\n",
+		"rules.json": `[{"name": "日番", "media_type": "tv", "genre_ids": [16], "countries": ["JP"]}, {"name": "其他"}]`,
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		rules, err := LoadCategoryRules(file)
+		if err != nil || !reflect.DeepEqual(rules, want) {
+			t.Errorf("LoadCategoryRules(%s) = %+v, %v", name, rules, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"rules.toml":     "",
+		"broken.json":    "[{",
+		"broken.yaml":    "- name: [",
+		"not_exist.yaml": "",
+	}
+	for name, content := range invalid {
+		file := filepath.Join(dir, name)
+		if content != "" {
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := LoadCategoryRules(file); err == nil {
+			t.Errorf("LoadCategoryRules(%s) succeeded", name)
+		}
+	}
+}
+
+func TestSetCategoryRules(t *testing.T) {
+	defer func() { _ = SetCategoryRules(nil) }()
+	m := &Meta{MediaType: MediaTypeMovie, OriginalLanguage: "zh"}
+	if got := Classify(m); got != "华语电影" {
+		t.Errorf("Classify = %q, want 华语电影", got)
+	}
+	if err := SetCategoryRules([]CategoryRule{{Name: "电影", MediaType: "movie"}}); err != nil {
+		t.Fatalf("SetCategoryRules failed, %s", err.Error())
+	}
+	if got := Classify(m); got != "电影" {
+		t.Errorf("Classify = %q, want 电影", got)
+	}
+	// 规则无效时保留原规则
+	if err := SetCategoryRules([]CategoryRule{{Name: ""}}); !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("SetCategoryRules error %v, want %v", err, ErrInvalidCategory)
+	}
+	if got := Classify(m); got != "电影" {
+		t.Errorf("Classify = %q, want 电影", got)
+	}
+	if err := SetCategoryRules(nil); err != nil || Classify(m) != "华语电影" {
+		t.Errorf("SetCategoryRules(nil) did not restore default rules, %v", err)
+	}
+}
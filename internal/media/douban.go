@@ -0,0 +1,176 @@
+package media
+
+import (
+	"github.com/hashicorp/golang-lru/arc/v2"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+const (
+	DoubanSuggestURL = "https://movie.douban.com/j/subject_suggest"
+	DoubanApiURL     = "https://m.douban.com/rexxar/api/v2"
+	DoubanUserAgent  = "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
+)
+
+var (
+	DoubanDateRe    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	DoubanRuntimeRe = regexp.MustCompile(`^\d+`)
+)
+
+// Douban 豆瓣，中文标题和评分更准确，没有 IMDB 等外部 ID
+type Douban struct {
+	client      http.Client
+	searchCache *arc.ARCCache[string, []*SearchResult]
+	detailCache *arc.ARCCache[string, *MediaDetail]
+}
+
+type doubanSuggest struct {
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	SubTitle string `json:"sub_title"`
+	Type     string `json:"type"`
+	Year     string `json:"year"`
+}
+
+type doubanSubject struct {
+	Id            string   `json:"id"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title"`
+	Year          string   `json:"year"`
+	IsTv          bool     `json:"is_tv"`
+	Genres        []string `json:"genres"`
+	Intro         string   `json:"intro"`
+	Pubdate       []string `json:"pubdate"`
+	Durations     []string `json:"durations"`
+	Rating        struct {
+		Value float32 `json:"value"`
+		Count int     `json:"count"`
+	} `json:"rating"`
+}
+
+func NewDouban(proxyUrl string) *Douban {
+	searchCache, err := arc.NewARC[string, []*SearchResult](512)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	detailCache, err := arc.NewARC[string, *MediaDetail](512)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return &Douban{
+		client:      newHttpClient(proxyUrl),
+		searchCache: searchCache,
+		detailCache: detailCache,
+	}
+}
+
+func (d *Douban) Name() string {
+	return ProviderDouban
+}
+
+func (d *Douban) get(u string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", DoubanUserAgent)
+	req.Header.Set("Referer", "https://m.douban.com/movie/")
+	return getJson(&d.client, req, v)
+}
+
+// SearchMedia 使用搜索建议接口，结果没有热度，按返回顺序递减
+func (d *Douban) SearchMedia(name string) ([]*SearchResult, error) {
+	if results, ok := d.searchCache.Get(name); ok {
+		return results, nil
+	}
+	var suggests []doubanSuggest
+	if err := d.get(DoubanSuggestURL+"?q="+url.QueryEscape(name), &suggests); err != nil {
+		return nil, err
+	}
+	results := make([]*SearchResult, 0, len(suggests))
+	for i, s := range suggests {
+		result := &SearchResult{
+			Provider:      ProviderDouban,
+			Id:            s.Id,
+			Title:         s.Title,
+			OriginalTitle: s.SubTitle,
+			Popularity:    float32(len(suggests) - i),
+		}
+		switch s.Type {
+		case "movie":
+			result.MediaType = MediaTypeMovie
+		case "tv":
+			result.MediaType = MediaTypeTv
+		default:
+			continue
+		}
+		result.Year, _ = strconv.Atoi(s.Year)
+		results = append(results, result)
+	}
+	d.searchCache.Add(name, results)
+	return results, nil
+}
+
+// GetMovieInfo 实现 Provider
+func (d *Douban) GetMovieInfo(id string) (*MediaDetail, error) {
+	return d.getSubject("movie", id)
+}
+
+// GetTvInfo 实现 Provider
+func (d *Douban) GetTvInfo(id string) (*MediaDetail, error) {
+	return d.getSubject("tv", id)
+}
+
+// GetExternalIds 实现 Provider，豆瓣只有自己的 ID
+func (d *Douban) GetExternalIds(id string, _ int) (*ExternalIds, error) {
+	doubanId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalIds{DoubanId: doubanId}, nil
+}
+
+func (d *Douban) getSubject(kind string, id string) (*MediaDetail, error) {
+	key := kind + ":" + id
+	if detail, ok := d.detailCache.Get(key); ok {
+		return detail, nil
+	}
+	doubanId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	subject := new(doubanSubject)
+	if err = d.get(DoubanApiURL+"/"+kind+"/"+id, subject); err != nil {
+		return nil, err
+	}
+	detail := &MediaDetail{
+		Provider:      ProviderDouban,
+		Id:            id,
+		MediaType:     MediaTypeMovie,
+		Title:         subject.Title,
+		OriginalTitle: subject.OriginalTitle,
+		Genres:        subject.Genres,
+		Overview:      subject.Intro,
+		Rating:        subject.Rating.Value,
+		Ids:           ExternalIds{DoubanId: doubanId},
+	}
+	if subject.IsTv || kind == "tv" {
+		detail.MediaType = MediaTypeTv
+	}
+	detail.Year, _ = strconv.Atoi(subject.Year)
+	// 上映日期形如 2019-02-05(中国大陆)，片长形如 125分钟
+	for _, date := range subject.Pubdate {
+		if match := DoubanDateRe.FindString(date); match != "" {
+			detail.ReleaseDate = match
+			break
+		}
+	}
+	if len(subject.Durations) > 0 {
+		detail.Runtime, _ = strconv.Atoi(DoubanRuntimeRe.FindString(subject.Durations[0]))
+	}
+	d.detailCache.Add(key, detail)
+	return detail, nil
+}
@@ -0,0 +1,101 @@
+package media
+
+import (
+	"mediahub/internal/utils"
+	"regexp"
+	"strings"
+)
+
+// Edition 版本，Re 匹配以空格连接的 1~3 个 token
+type Edition struct {
+	Name string
+	Re   *regexp.Regexp
+}
+
+// Editions 已知版本，靠前的优先
+var Editions = []Edition{
+	{"Director's Cut", regexp.MustCompile(`(?i)^(?:directors?'?s? cut|导演剪辑版|导演版)$`)},
+	{"Extended", regexp.MustCompile(`(?i)^(?:extended(?: (?:cut|edition|version))?|加长版)$`)},
+	{"Theatrical", regexp.MustCompile(`(?i)^(?:theatrical(?: (?:cut|edition|version))?|院线版)$`)},
+	{"Final Cut", regexp.MustCompile(`(?i)^(?:the )?final cut$`)},
+	{"Ultimate Cut", regexp.MustCompile(`(?i)^ultimate (?:cut|edition)$`)},
+	{"Uncut", regexp.MustCompile(`(?i)^(?:uncut|未删减版?|无删减版?)$`)},
+	{"Unrated", regexp.MustCompile(`(?i)^unrated$`)},
+	{"IMAX", regexp.MustCompile(`(?i)^(?:imax(?: (?:edition|enhanced))?|IMAX版)$`)},
+	{"Remastered", regexp.MustCompile(`(?i)^(?:(?:4K )?remaster(?:ed)?(?: edition)?|重制版|修复版)$`)},
+	{"Criterion", regexp.MustCompile(`(?i)^criterion(?: collection)?$`)},
+	{"Special Edition", regexp.MustCompile(`(?i)^special edition$`)},
+	{"Collector's Edition", regexp.MustCompile(`(?i)^collector'?s edition$`)},
+	{"Anniversary Edition", regexp.MustCompile(`(?i)^(?:\d{1,3}(?:st|nd|rd|th) )?anniversary edition$`)},
+	{"Open Matte", regexp.MustCompile(`(?i)^open matte$`)},
+}
+
+// MatchEdition 识别版本名称
+func MatchEdition(text string) string {
+	for _, e := range Editions {
+		if e.Re.MatchString(text) {
+			return e.Name
+		}
+	}
+	return ""
+}
+
+type ParseEdition struct {
+	*ParseStep
+}
+
+func NewParseEdition(m *Meta) Step {
+	return &ParseEdition{
+		ParseStep: NewParseStep(m),
+	}
+}
+
+// Run 识别版本，优先匹配更多的 token，识别后的 token 不再进入名称
+func (p *ParseEdition) Run(ctx *Parser, token string) bool {
+	// 英文版本词只在名称之后识别，避免误伤 Extended Family 之类的标题
+	if p.meta.GetName() == "" && !utils.IsChinese(token) {
+		return true
+	}
+	for n := 3; n > 0; n-- {
+		words := []string{token}
+		for i := 1; i < n; i++ {
+			next, err := ctx.token.PeekN(i)
+			if err != nil || next == "" {
+				break
+			}
+			words = append(words, next)
+		}
+		if len(words) != n {
+			continue
+		}
+		edition := MatchEdition(strings.Join(words, " "))
+		if edition == "" || n == 1 && !utils.IsChinese(token) && !editionEnd(ctx) {
+			continue
+		}
+		if !strings.Contains(p.meta.Edition, edition) {
+			p.meta.Edition = strings.TrimSpace(p.meta.Edition + " " + edition)
+		}
+		for i := 1; i < n; i++ {
+			ctx.token.Next()
+		}
+		if p.meta.GetName() != "" {
+			ctx.SetFlag(ParseFlagName)
+		}
+		return false
+	}
+	return true
+}
+
+// editionEnd 单个英文版本词需要出现在名称结束之后，或后面紧跟年份、技术参数
+func editionEnd(ctx *Parser) bool {
+	if ctx.CheckFlag(ParseFlagName) {
+		return true
+	}
+	next, err := ctx.token.Peek()
+	if err != nil || next == "" {
+		return true
+	}
+	return DigitRe.MatchString(next) && len(next) == 4 ||
+		ResourcesPixRe.MatchString(next) || ResourcesPixRe2.MatchString(next) ||
+		SourceRe.MatchString(next) || EffectRe.MatchString(next) || MatchEdition(next) != ""
+}
@@ -0,0 +1,53 @@
+package media
+
+import "testing"
+
+func TestMatchEdition(t *testing.T) {
+	tests := map[string]string{
+		"Directors Cut":            "Director's Cut",
+		"director's cut":           "Director's Cut",
+		"导演剪辑版":                    "Director's Cut",
+		"Extended Edition":         "Extended",
+		"The Final Cut":            "Final Cut",
+		"未删减":                      "Uncut",
+		"IMAX Enhanced":            "IMAX",
+		"4K Remastered":            "Remastered",
+		"Criterion Collection":     "Criterion",
+		"30th Anniversary Edition": "Anniversary Edition",
+		"Collectors Edition":       "Collector's Edition",
+		"Extended Family":          "",
+		"Cut":                      "",
+	}
+	for text, want := range tests {
+		if got := MatchEdition(text); got != want {
+			t.Errorf("MatchEdition(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestParseEdition(t *testing.T) {
+	tests := []struct {
+		title, name, edition string
+		year                 int
+	}{
+		{"Blade.Runner.1982.The.Final.Cut.1080p.BluRay.x264", "Blade Runner", "Final Cut", 1982},
+		{"Blade.Runner.Final.Cut.1982.1080p", "Blade Runner", "Final Cut", 1982},
+		{"Apocalypse.Now.1979.Directors.Cut.1080p.BluRay", "Apocalypse Now", "Director's Cut", 1979},
+		{"Aliens.1986.Extended.Edition.1080p.BluRay", "Aliens", "Extended", 1986},
+		{"Oppenheimer.2023.IMAX.2160p.WEB-DL", "Oppenheimer", "IMAX", 2023},
+		{"Back.to.the.Future.1985.30th.Anniversary.Edition.1080p", "Back to the Future", "Anniversary Edition", 1985},
+		{"Die.Hard.1988.Unrated.Extended.1080p", "Die Hard", "Unrated Extended", 1988},
+		{"色戒.2007.未删减版.1080p.BluRay", "色戒", "Uncut", 2007},
+		{"天国王朝 [导演剪辑版] 2005 1080p", "天国王朝", "Director's Cut", 2005},
+		{"[导演剪辑版] 天国王朝 2005 1080p", "天国王朝", "Director's Cut", 2005},
+		// 名称之前的英文版本词是名称的一部分
+		{"Extended.Family.2023.1080p.WEB-DL", "Extended Family", "", 2023},
+		{"Unrated.2023.1080p", "Unrated", "", 2023},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, false).GetMeta()
+		if m.GetName() != tt.name || m.Edition != tt.edition || m.Year != tt.year {
+			t.Errorf("NewMeta(%q) = %q %q %d, want %q %q %d", tt.title, m.GetName(), m.Edition, m.Year, tt.name, tt.edition, tt.year)
+		}
+	}
+}
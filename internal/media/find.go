@@ -0,0 +1,104 @@
+package media
+
+import (
+	tmdb "github.com/cyruzin/golang-tmdb"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	TmdbSourceImdb = "imdb_id"
+	TmdbSourceTvdb = "tvdb_id"
+)
+
+var (
+	// ExternalIdRe 标题中的 ID 标签，如 {tmdb-12345}、[imdbid-tt0111161]、{tvdb-81189}
+	ExternalIdRe = regexp.MustCompile(`(?i)[\[{]\s*(tmdb|imdb|tvdb|douban)(?:id)?\s*[-=:]\s*(tt\d{7,8}|\d+)\s*[\]}]`)
+	// ImdbLinkRe 种子简介中的 IMDb 链接
+	ImdbLinkRe = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.|m\.)?imdb\.com/title/(tt\d{7,8})/?`)
+	ImdbIdRe   = regexp.MustCompile(`^tt\d{7,8}$`)
+)
+
+// ParseExternalIds 识别并去掉标题中的 ID 标签和 IMDb 链接
+func ParseExternalIds(text string) (ExternalIds, string) {
+	var ids ExternalIds
+	if !strings.ContainsAny(text, "[{") && !strings.Contains(strings.ToLower(text), "imdb") {
+		return ids, text
+	}
+	text = ExternalIdRe.ReplaceAllStringFunc(text, func(tag string) string {
+		match := ExternalIdRe.FindStringSubmatch(tag)
+		id := match[2]
+		switch strings.ToLower(match[1]) {
+		case "imdb":
+			if ImdbIdRe.MatchString(id) {
+				ids.ImdbId = id
+			}
+		case "tmdb":
+			ids.TmdbId, _ = strconv.Atoi(id)
+		case "tvdb":
+			ids.TvdbId, _ = strconv.Atoi(id)
+		case "douban":
+			ids.DoubanId, _ = strconv.Atoi(id)
+		}
+		return " "
+	})
+	if match := ImdbLinkRe.FindStringSubmatch(text); match != nil {
+		if ids.ImdbId == "" {
+			ids.ImdbId = match[1]
+		}
+		text = ImdbLinkRe.ReplaceAllString(text, " ")
+	}
+	return ids, strings.TrimSpace(text)
+}
+
+// setIds 补充 meta 中为空的 ID
+func (m *Meta) setIds(ids ExternalIds) {
+	current := ExternalIds{TmdbId: m.TmdbId, ImdbId: m.ImdbId, TvdbId: m.TvdbId, DoubanId: m.DoubanId}
+	current.Merge(ids)
+	m.TmdbId, m.ImdbId, m.TvdbId, m.DoubanId = current.TmdbId, current.ImdbId, current.TvdbId, current.DoubanId
+}
+
+// FindByExternalId 通过 TMDB find 接口按外部 ID 查找，source 为 TmdbSourceImdb、TmdbSourceTvdb
+func (t *Tmdb) FindByExternalId(id string, source string) ([]*SearchResult, error) {
+	find, err := tmdbLoad(t, t.cacheKey(TmdbCacheFind, source+"-"+id), t.detailTTL, func() (*tmdb.FindByID, error) {
+		return t.client.GetFindByID(id, t.withOptions("external_source", source))
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SearchResult, 0, len(find.MovieResults)+len(find.TvResults))
+	for _, r := range find.MovieResults {
+		results = append(results, &SearchResult{
+			Provider:      ProviderTmdb,
+			Id:            strconv.FormatInt(r.ID, 10),
+			MediaType:     MediaTypeMovie,
+			Title:         r.Title,
+			OriginalTitle: r.OriginalTitle,
+			Year:          dateYear(r.ReleaseDate),
+			Popularity:    r.Popularity,
+		})
+	}
+	for _, r := range find.TvResults {
+		results = append(results, &SearchResult{
+			Provider:      ProviderTmdb,
+			Id:            strconv.FormatInt(r.ID, 10),
+			MediaType:     MediaTypeTv,
+			Title:         r.Name,
+			OriginalTitle: r.OriginalName,
+			Year:          dateYear(r.FirstAirDate),
+			Popularity:    r.Popularity,
+		})
+	}
+	return results, nil
+}
+
+// FindByImdbId 按 IMDb ID 查找
+func (t *Tmdb) FindByImdbId(imdbId string) ([]*SearchResult, error) {
+	return t.FindByExternalId(imdbId, TmdbSourceImdb)
+}
+
+// FindByTvdbId 按 TVDB ID 查找
+func (t *Tmdb) FindByTvdbId(tvdbId int) ([]*SearchResult, error) {
+	return t.FindByExternalId(strconv.Itoa(tvdbId), TmdbSourceTvdb)
+}
@@ -0,0 +1,56 @@
+package media
+
+import "testing"
+
+func TestParseExternalIds(t *testing.T) {
+	tests := []struct {
+		text string
+		ids  ExternalIds
+		rest string
+	}{
+		{"Inception (2010) {tmdb-27205}", ExternalIds{TmdbId: 27205}, "Inception (2010)"},
+		{"Inception (2010) [imdbid-tt1375666]", ExternalIds{ImdbId: "tt1375666"}, "Inception (2010)"},
+		{"Show {tvdb-81189} S01E01", ExternalIds{TvdbId: 81189}, "Show   S01E01"},
+		{"Movie [doubanid=3541415]", ExternalIds{DoubanId: 3541415}, "Movie"},
+		{"Movie {IMDB: tt0111161 } 1080p", ExternalIds{ImdbId: "tt0111161"}, "Movie   1080p"},
+		{"Movie {tmdb-1}{tvdb-2}", ExternalIds{TmdbId: 1, TvdbId: 2}, "Movie"},
+		{"Movie https://www.imdb.com/title/tt0111161/ 1080p", ExternalIds{ImdbId: "tt0111161"}, "Movie   1080p"},
+		// 标签优先于链接
+		{"Movie [imdbid-tt1375666] imdb.com/title/tt0111161", ExternalIds{ImdbId: "tt1375666"}, "Movie"},
+		// 格式不对的不识别，保留原文
+		{"Movie [imdb-tt123]", ExternalIds{}, "Movie [imdb-tt123]"},
+		{"Movie [tmdb-abc]", ExternalIds{}, "Movie [tmdb-abc]"},
+		{"Inception.2010.1080p", ExternalIds{}, "Inception.2010.1080p"},
+	}
+	for _, tt := range tests {
+		ids, rest := ParseExternalIds(tt.text)
+		if ids != tt.ids || rest != tt.rest {
+			t.Errorf("ParseExternalIds(%q) = %+v %q, want %+v %q", tt.text, ids, rest, tt.ids, tt.rest)
+		}
+	}
+}
+
+func TestMetaExternalIds(t *testing.T) {
+	tests := []struct {
+		title   string
+		name    string
+		ids     ExternalIds
+		episode int
+	}{
+		{"Inception (2010) {tmdb-27205}.mkv", "Inception", ExternalIds{TmdbId: 27205}, 0},
+		{"Breaking Bad {tvdb-81189} S01E02 1080p", "Breaking Bad", ExternalIds{TvdbId: 81189}, 2},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, false).GetMeta()
+		ids := ExternalIds{TmdbId: m.TmdbId, ImdbId: m.ImdbId, TvdbId: m.TvdbId, DoubanId: m.DoubanId}
+		if m.GetName() != tt.name || ids != tt.ids || m.BeginEpisode != tt.episode {
+			t.Errorf("NewMeta(%q) = %q %+v E%d", tt.title, m.GetName(), ids, m.BeginEpisode)
+		}
+	}
+
+	m := &Meta{TmdbId: 1}
+	m.setIds(ExternalIds{TmdbId: 2, ImdbId: "tt1375666"})
+	if m.TmdbId != 1 || m.ImdbId != "tt1375666" {
+		t.Errorf("setIds = %d %q", m.TmdbId, m.ImdbId)
+	}
+}
@@ -0,0 +1,166 @@
+package media
+
+import (
+	"mediahub/internal/utils"
+	"regexp"
+	"strings"
+)
+
+// LanguageTags 语言标签对应的语言代码，标签不区分大小写
+var LanguageTags = map[string]string{
+	"chs": "zh-Hans", "sc": "zh-Hans", "gb": "zh-Hans", "zhs": "zh-Hans", "zh-hans": "zh-Hans", "zh-cn": "zh-Hans",
+	"zh_cn": "zh-Hans", "hans": "zh-Hans", "简体": "zh-Hans", "简中": "zh-Hans", "简": "zh-Hans",
+	"cht": "zh-Hant", "tc": "zh-Hant", "big5": "zh-Hant", "zht": "zh-Hant", "zh-hant": "zh-Hant", "zh-tw": "zh-Hant",
+	"zh_tw": "zh-Hant", "zh-hk": "zh-Hant", "hant": "zh-Hant", "繁体": "zh-Hant", "繁體": "zh-Hant", "繁中": "zh-Hant", "繁": "zh-Hant",
+	"zh": "zh", "chi": "zh", "zho": "zh", "chinese": "zh", "中文": "zh", "中字": "zh",
+	"en": "en", "eng": "en", "english": "en", "英文": "en", "英": "en",
+	"ja": "ja", "jp": "ja", "jpn": "ja", "japanese": "ja", "日文": "ja", "日语": "ja", "日": "ja",
+	"ko": "ko", "kor": "ko", "korean": "ko", "韩文": "ko", "韩语": "ko",
+}
+
+// LanguageCode 语言标签转换为语言代码，未知标签返回空
+func LanguageCode(tag string) string {
+	return LanguageTags[strings.ToLower(strings.TrimSpace(tag))]
+}
+
+var (
+	LanguageTagRe     = regexp.MustCompile(`^[国粤英日韩法德俄泰中普通话简繁體体文双雙三多语語字幕配音轨軌内內封嵌外挂掛]+$`)
+	LanguageAudioRe   = regexp.MustCompile(`^(.*?)(?:双|雙|三|多)?(?:语|語|配音|音轨|音軌)`)
+	LanguageAudioEnRe = regexp.MustCompile(`(?i)^(?:mandarin|cantonese|multi audio|dual audio)$`)
+	LanguageSubRe     = regexp.MustCompile(`(?i)^(?:CHS|CHT|BIG5|ENG|JPN|KOR)$`)
+)
+
+var languageChars = []struct {
+	char  string
+	audio string
+	sub   string
+}{
+	{"普通话", "zh", ""}, {"国", "zh", ""}, {"粤", "yue", "yue"}, {"简", "", "zh-Hans"}, {"繁", "", "zh-Hant"},
+	{"中", "zh", "zh"}, {"英", "en", "en"}, {"日", "ja", "ja"}, {"韩", "ko", "ko"}, {"法", "fr", "fr"},
+	{"德", "de", "de"}, {"俄", "ru", "ru"}, {"泰", "th", "th"},
+}
+
+// ParseLanguageTag 识别 国语、国英双语、简繁英字幕、国粤双语中字 等语言标签，返回音轨和字幕语言
+func ParseLanguageTag(tag string) (audio []string, subs []string, ok bool) {
+	if LanguageAudioEnRe.MatchString(tag) {
+		switch strings.ToLower(tag) {
+		case "mandarin":
+			return []string{"zh"}, nil, true
+		case "cantonese":
+			return []string{"yue"}, nil, true
+		}
+		return []string{"mul"}, nil, true
+	}
+	if LanguageSubRe.MatchString(tag) {
+		return nil, []string{LanguageCode(tag)}, true
+	}
+	if !LanguageTagRe.MatchString(tag) {
+		return nil, nil, false
+	}
+	rest := tag
+	// 简日双语 之类带简繁的为字幕语言
+	if match := LanguageAudioRe.FindStringSubmatchIndex(tag); match != nil && !strings.ContainsAny(tag[match[2]:match[3]], "简繁") {
+		audio = languageCodes(tag[match[2]:match[3]], true)
+		rest = tag[match[1]:]
+		ok = true
+	}
+	if strings.ContainsAny(rest, "字幕简繁") {
+		subs = languageCodes(rest, false)
+		// 双字 为中英双字
+		if len(subs) == 0 && strings.Contains(rest, "双字") {
+			subs = []string{"zh", "en"}
+		}
+		// 内封字幕 等无语言信息的标签默认为中文
+		if len(subs) == 0 {
+			subs = []string{"zh"}
+		}
+		ok = true
+	}
+	return audio, subs, ok
+}
+
+func languageCodes(text string, audio bool) []string {
+	codes := make([]string, 0, 3)
+	hasHan := strings.ContainsAny(text, "简繁")
+	for _, c := range languageChars {
+		if !strings.Contains(text, c.char) {
+			continue
+		}
+		text = strings.ReplaceAll(text, c.char, "")
+		code := c.sub
+		if audio {
+			code = c.audio
+		} else if c.char == "中" && hasHan {
+			continue
+		}
+		codes = AppendLanguages(codes, code)
+	}
+	return codes
+}
+
+// AppendLanguages 去重追加语言代码
+func AppendLanguages(dst []string, langs ...string) []string {
+	for _, lang := range langs {
+		if lang == "" {
+			continue
+		}
+		found := false
+		for _, l := range dst {
+			if l == lang {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, lang)
+		}
+	}
+	return dst
+}
+
+type ParseLanguage struct {
+	*ParseStep
+}
+
+func NewParseLanguage(m *Meta) Step {
+	return &ParseLanguage{
+		ParseStep: NewParseStep(m),
+	}
+}
+
+func (p *ParseLanguage) Run(ctx *Parser, token string) bool {
+	// 英文标签只在名称之后识别
+	if p.meta.GetName() == "" && !utils.IsChinese(token) {
+		return true
+	}
+	text, skip := token, false
+	if next, err := ctx.token.Peek(); err == nil && LanguageAudioEnRe.MatchString(token+" "+next) {
+		text, skip = token+" "+next, true
+	}
+	audio, subs, ok := ParseLanguageTag(text)
+	if !ok {
+		return true
+	}
+	p.meta.AudioLanguages = AppendLanguages(p.meta.AudioLanguages, audio...)
+	p.meta.SubtitleLanguages = AppendLanguages(p.meta.SubtitleLanguages, subs...)
+	if skip {
+		ctx.token.Next()
+	}
+	if p.meta.GetName() != "" {
+		ctx.SetFlag(ParseFlagName)
+	}
+	return false
+}
+
+// parseLanguages 从副标题中识别语言标签
+func (m *Meta) parseLanguages(text string) {
+	if text == "" {
+		return
+	}
+	for _, word := range utils.SplitChars.Split(text, -1) {
+		if audio, subs, ok := ParseLanguageTag(word); ok {
+			m.AudioLanguages = AppendLanguages(m.AudioLanguages, audio...)
+			m.SubtitleLanguages = AppendLanguages(m.SubtitleLanguages, subs...)
+		}
+	}
+}
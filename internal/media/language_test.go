@@ -0,0 +1,65 @@
+package media
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLanguageTag(t *testing.T) {
+	tests := []struct {
+		tag   string
+		audio []string
+		subs  []string
+		ok    bool
+	}{
+		{"国语", []string{"zh"}, nil, true},
+		{"粤语", []string{"yue"}, nil, true},
+		{"国英双语", []string{"zh", "en"}, nil, true},
+		{"国粤双语中字", []string{"zh", "yue"}, []string{"zh"}, true},
+		{"简繁英字幕", nil, []string{"zh-Hans", "zh-Hant", "en"}, true},
+		{"简日双语", nil, []string{"zh-Hans", "ja"}, true},
+		{"中英字幕", nil, []string{"zh", "en"}, true},
+		{"内封简繁", nil, []string{"zh-Hans", "zh-Hant"}, true},
+		{"CHS", nil, []string{"zh-Hans"}, true},
+		{"English", nil, nil, false},
+	}
+	for _, tt := range tests {
+		audio, subs, ok := ParseLanguageTag(tt.tag)
+		if ok != tt.ok || len(audio) != len(tt.audio) || len(subs) != len(tt.subs) ||
+			len(audio) > 0 && !reflect.DeepEqual(audio, tt.audio) || len(subs) > 0 && !reflect.DeepEqual(subs, tt.subs) {
+			t.Errorf("ParseLanguageTag(%q) = %q, %q, %v, want %q, %q, %v", tt.tag, audio, subs, ok, tt.audio, tt.subs, tt.ok)
+		}
+	}
+}
+
+func TestAppendLanguages(t *testing.T) {
+	got := AppendLanguages([]string{"zh"}, "en", "", "zh", "ja", "en")
+	if want := []string{"zh", "en", "ja"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppendLanguages = %q, want %q", got, want)
+	}
+}
+
+func TestMetaLanguages(t *testing.T) {
+	tests := []struct {
+		title string
+		audio []string
+		subs  []string
+	}{
+		{"流浪地球.2019.国英双语.1080p", []string{"zh", "en"}, nil},
+		{"Movie.2019.1080p.BluRay.x264.DTS.CHS.ENG-GRP", nil, []string{"zh-Hans", "en"}},
+		{"[Lilith-Raws] 间谍过家家 / Spy x Family - 05 [Baha][WEB-DL][1080p][AVC AAC][CHT][MP4]", nil, []string{"zh-Hant"}},
+		{"[桜都字幕组][间谍过家家 第二季][第05话][简日双语][1080P]", nil, []string{"zh-Hans", "ja"}},
+		// 动漫中简繁标签和 CHS、CHT 重复时去重，顺序与出现顺序一致
+		{"[ANi] Frieren - 05 [1080P][CHS&CHT].mp4", nil, []string{"zh-Hans", "zh-Hant"}},
+		{"[ANi] Frieren - 05 [1080P][简繁内封][CHS][CHT].mp4", nil, []string{"zh-Hans", "zh-Hant"}},
+		{"[Nekomoe kissaten][Frieren][05][1080p][JPSC].mp4", nil, []string{"ja", "zh-Hans"}},
+		{"[Nekomoe kissaten][Frieren][05][1080p][国语配音][CHS].mp4", []string{"zh"}, []string{"zh-Hans"}},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, IsMediaFile(tt.title)).GetMeta()
+		if len(m.AudioLanguages) != len(tt.audio) || len(tt.audio) > 0 && !reflect.DeepEqual(m.AudioLanguages, tt.audio) ||
+			len(m.SubtitleLanguages) != len(tt.subs) || len(tt.subs) > 0 && !reflect.DeepEqual(m.SubtitleLanguages, tt.subs) {
+			t.Errorf("%q: audio %q subs %q, want %q %q", tt.title, m.AudioLanguages, m.SubtitleLanguages, tt.audio, tt.subs)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package media
+
+import (
+	"errors"
+	"math"
+	"mediahub/internal/normalize"
+	"strconv"
+)
+
+const (
+	MatchMinSimilarity = 0.6 // 标题相似度下限
+	MatchTypePenalty   = 10  // 识别为电影但结果为电视剧的扣分
+	MatchPopularityMax = 10  // 热度最多加分
+	MatchAliasMax      = 3   // 标题都不相似时，最多获取几个结果的其他标题
+)
+
+var (
+	ErrMediaNotFound     = errors.New("media not found")
+	ErrTmdbNotConfigured = errors.New("tmdb not configured")
+)
+
+// matchQuery 搜索结果的匹配条件
+type matchQuery struct {
+	names     []string
+	mediaType int  // 识别的类型
+	strict    bool // 类型已由优先级更高的来源确定，只接受同类型结果
+	year      int
+	season    int
+}
+
+func newMatchQuery(m *Meta) *matchQuery {
+	return &matchQuery{
+		names:     searchNames(m),
+		mediaType: m.MediaType,
+		year:      m.Year,
+		season:    m.BeginSeason,
+	}
+}
+
+// withDetail 用已匹配的详情约束后续来源：加入标准标题，固定类型和年份
+func (q *matchQuery) withDetail(d *MediaDetail) *matchQuery {
+	names := append(append([]string{}, q.names...), d.Title, d.OriginalTitle)
+	return &matchQuery{
+		names:     dedupNames(names),
+		mediaType: d.MediaType,
+		strict:    true,
+		year:      d.Year,
+		season:    q.season,
+	}
+}
+
+// searchNames 用于搜索的名称，去重
+func searchNames(m *Meta) []string {
+	return dedupNames([]string{m.CnName, m.EnName, m.JpName, m.KrName})
+}
+
+func dedupNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	keys := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := normalize.Key(name)
+		if key == "" || keys[key] {
+			continue
+		}
+		keys[key] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// best 搜索全部名称，返回得分最高的结果。
+// 标题都不相似时，再用类型和年份符合的前几个结果的其他标题匹配，如用英文名搜索华语电影
+func (q *matchQuery) best(p Provider) (*SearchResult, error) {
+	var best *SearchResult
+	var others []*SearchResult
+	for _, name := range q.names {
+		results, err := p.SearchMedia(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			c := *r
+			if !q.accept(&c) {
+				continue
+			}
+			if !q.score(&c) {
+				others = append(others, &c)
+				continue
+			}
+			if best == nil || c.Score > best.Score {
+				best = &c
+			}
+		}
+	}
+	if best == nil {
+		best = q.bestAlias(p, others)
+	}
+	if best == nil {
+		return nil, ErrMediaNotFound
+	}
+	return best, nil
+}
+
+// bestAlias 获取结果详情中的其他标题重新打分
+func (q *matchQuery) bestAlias(p Provider, others []*SearchResult) *SearchResult {
+	var best *SearchResult
+	checked := make(map[string]bool, MatchAliasMax)
+	for _, c := range others {
+		key := c.Id + ":" + strconv.Itoa(c.MediaType)
+		if checked[key] {
+			continue
+		}
+		if len(checked) >= MatchAliasMax {
+			break
+		}
+		checked[key] = true
+		detail, err := getDetail(p, c.Id, c.MediaType)
+		if err != nil || len(detail.AlsoKnownAs) == 0 {
+			continue
+		}
+		if q.score(c, detail.AlsoKnownAs...) && (best == nil || c.Score > best.Score) {
+			best = c
+		}
+	}
+	return best
+}
+
+// accept 按类型和年份过滤
+//
+// 识别为电视剧时只接受电视剧；识别为电影时也接受电视剧，因为没有季集信息的剧集也会被识别为电影。
+// 年份相差超过 1 年的过滤掉，第二季以后的剧集年份是当季年份，不过滤。
+func (q *matchQuery) accept(c *SearchResult) bool {
+	if (q.mediaType == MediaTypeTv || q.strict) && c.MediaType != q.mediaType {
+		return false
+	}
+	if q.year != 0 && c.Year != 0 && yearDiff(q.year, c.Year) > 1 {
+		return c.MediaType == MediaTypeTv && q.season > 1
+	}
+	return true
+}
+
+// score 按标题相似度、年份和热度打分，aliases 为结果的其他标题，返回标题是否相似。
+// 识别为电影时电视剧扣分
+func (q *matchQuery) score(c *SearchResult, aliases ...string) bool {
+	similarity := 0.0
+	for _, name := range q.names {
+		for _, title := range append([]string{c.Title, c.OriginalTitle}, aliases...) {
+			similarity = math.Max(similarity, normalize.Similarity(name, title))
+		}
+	}
+	if similarity < MatchMinSimilarity {
+		return false
+	}
+	score := similarity * 100
+	if q.mediaType != MediaTypeTv && c.MediaType == MediaTypeTv {
+		score -= MatchTypePenalty
+	}
+	if q.year != 0 && c.Year != 0 {
+		switch yearDiff(q.year, c.Year) {
+		case 0:
+			score += 10
+		case 1:
+			score += 5
+		}
+	}
+	score += math.Min(math.Log1p(float64(c.Popularity)), MatchPopularityMax)
+	c.Score = score
+	return true
+}
+
+func yearDiff(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// dateYear 从 yyyy-mm-dd 中取年份
+func dateYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
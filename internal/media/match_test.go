@@ -0,0 +1,169 @@
+package media
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fakeProvider 按名称返回固定搜索结果，记录详情请求次数
+type fakeProvider struct {
+	name    string
+	results map[string][]*SearchResult
+	details map[string]*MediaDetail
+	err     error
+	gets    int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) SearchMedia(name string) ([]*SearchResult, error) {
+	return p.results[name], p.err
+}
+
+func (p *fakeProvider) GetMovieInfo(id string) (*MediaDetail, error) {
+	return p.detail(id)
+}
+
+func (p *fakeProvider) GetTvInfo(id string) (*MediaDetail, error) {
+	return p.detail(id)
+}
+
+func (p *fakeProvider) GetExternalIds(string, int) (*ExternalIds, error) {
+	return &ExternalIds{}, nil
+}
+
+func (p *fakeProvider) detail(id string) (*MediaDetail, error) {
+	p.gets++
+	if d, ok := p.details[id]; ok {
+		return d, nil
+	}
+	return nil, ErrMediaNotFound
+}
+
+func TestMatchAccept(t *testing.T) {
+	tests := []struct {
+		query matchQuery
+		c     SearchResult
+		want  bool
+	}{
+		{matchQuery{mediaType: MediaTypeMovie}, SearchResult{MediaType: MediaTypeMovie}, true},
+		{matchQuery{mediaType: MediaTypeMovie}, SearchResult{MediaType: MediaTypeTv}, true},
+		{matchQuery{mediaType: MediaTypeTv}, SearchResult{MediaType: MediaTypeMovie}, false},
+		{matchQuery{mediaType: MediaTypeMovie, strict: true}, SearchResult{MediaType: MediaTypeTv}, false},
+		{matchQuery{mediaType: MediaTypeMovie, year: 2010}, SearchResult{MediaType: MediaTypeMovie, Year: 2011}, true},
+		{matchQuery{mediaType: MediaTypeMovie, year: 2010}, SearchResult{MediaType: MediaTypeMovie, Year: 2012}, false},
+		{matchQuery{mediaType: MediaTypeMovie, year: 2010}, SearchResult{MediaType: MediaTypeMovie}, true},
+		{matchQuery{mediaType: MediaTypeTv, year: 2023, season: 2}, SearchResult{MediaType: MediaTypeTv, Year: 2019}, true},
+		{matchQuery{mediaType: MediaTypeTv, year: 2023, season: 1}, SearchResult{MediaType: MediaTypeTv, Year: 2019}, false},
+	}
+	for i, tt := range tests {
+		if got := tt.query.accept(&tt.c); got != tt.want {
+			t.Errorf("accept #%d = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		query   matchQuery
+		c       SearchResult
+		aliases []string
+		ok      bool
+		score   float64
+	}{
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie}, SearchResult{Title: "盗梦空间", OriginalTitle: "Inception", MediaType: MediaTypeMovie}, nil, true, 100},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie, year: 2010}, SearchResult{Title: "Inception", MediaType: MediaTypeMovie, Year: 2010}, nil, true, 110},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie, year: 2010}, SearchResult{Title: "Inception", MediaType: MediaTypeMovie, Year: 2011}, nil, true, 105},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie}, SearchResult{Title: "Inception", MediaType: MediaTypeTv}, nil, true, 100 - MatchTypePenalty},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeTv}, SearchResult{Title: "Inception", MediaType: MediaTypeTv}, nil, true, 100},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie}, SearchResult{Title: "Inception", Popularity: float32(math.E - 1)}, nil, true, 101},
+		{matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie}, SearchResult{Title: "Inception", Popularity: 1e9}, nil, true, 100 + MatchPopularityMax},
+		{matchQuery{names: []string{"The Wandering Earth"}, mediaType: MediaTypeMovie}, SearchResult{Title: "流浪地球"}, nil, false, 0},
+		{matchQuery{names: []string{"The Wandering Earth"}, mediaType: MediaTypeMovie}, SearchResult{Title: "流浪地球"}, []string{"The Wandering Earth"}, true, 100},
+	}
+	for i, tt := range tests {
+		c := tt.c
+		ok := tt.query.score(&c, tt.aliases...)
+		if ok != tt.ok || math.Abs(c.Score-tt.score) > 1e-6 {
+			t.Errorf("score #%d = %v %.2f, want %v %.2f", i, ok, c.Score, tt.ok, tt.score)
+		}
+	}
+}
+
+func TestMatchBest(t *testing.T) {
+	p := &fakeProvider{
+		results: map[string][]*SearchResult{
+			"Inception": {
+				{Id: "1", Title: "Inception: The Cobol Job", MediaType: MediaTypeMovie, Year: 2010},
+				{Id: "2", Title: "Inception", MediaType: MediaTypeMovie, Year: 2010},
+				{Id: "3", Title: "Inception", MediaType: MediaTypeMovie, Year: 1990},
+			},
+			"盗梦空间": {{Id: "2", Title: "盗梦空间", MediaType: MediaTypeMovie, Year: 2010, Popularity: 50}},
+			"The Wandering Earth": {
+				{Id: "10", Title: "流浪地球2", MediaType: MediaTypeMovie, Year: 2023},
+				{Id: "11", Title: "流浪地球", MediaType: MediaTypeMovie, Year: 2019},
+			},
+		},
+		details: map[string]*MediaDetail{
+			"10": {Id: "10", AlsoKnownAs: []string{"The Wandering Earth II"}},
+			"11": {Id: "11", AlsoKnownAs: []string{"The Wandering Earth"}},
+		},
+	}
+	q := &matchQuery{names: []string{"Inception", "盗梦空间"}, mediaType: MediaTypeMovie, year: 2010}
+	if r, err := q.best(p); err != nil || r.Id != "2" || r.Title != "盗梦空间" {
+		t.Errorf("best(Inception) = %+v, %v", r, err)
+	}
+	// 搜索结果不被修改
+	if p.results["盗梦空间"][0].Score != 0 {
+		t.Errorf("best modified search result")
+	}
+
+	// 标题都不相似时用其他标题匹配，年份不符的不获取详情
+	q = &matchQuery{names: []string{"The Wandering Earth"}, mediaType: MediaTypeMovie, year: 2019}
+	if r, err := q.best(p); err != nil || r.Id != "11" || p.gets != 1 {
+		t.Errorf("best(The Wandering Earth) = %+v, %v, %d details", r, err, p.gets)
+	}
+
+	p.gets = 0
+	p.results["Frieren"] = nil
+	for i := 0; i < MatchAliasMax+2; i++ {
+		id := string(rune('a' + i))
+		p.results["Frieren"] = append(p.results["Frieren"], &SearchResult{Id: id, Title: "葬送的芙莉莲", MediaType: MediaTypeTv})
+	}
+	q = &matchQuery{names: []string{"Frieren"}, mediaType: MediaTypeTv}
+	if _, err := q.best(p); !errors.Is(err, ErrMediaNotFound) || p.gets != MatchAliasMax {
+		t.Errorf("best(Frieren) = %v, %d details, want %v, %d", err, p.gets, ErrMediaNotFound, MatchAliasMax)
+	}
+
+	p.err = errors.New("search failed")
+	if _, err := q.best(p); err != p.err {
+		t.Errorf("best error = %v, want %v", err, p.err)
+	}
+}
+
+func TestMatchWithDetail(t *testing.T) {
+	q := &matchQuery{names: []string{"Inception"}, mediaType: MediaTypeMovie, season: 1}
+	got := q.withDetail(&MediaDetail{Title: "盗梦空间", OriginalTitle: "Inception", MediaType: MediaTypeTv, Year: 2010})
+	want := &matchQuery{names: []string{"Inception", "盗梦空间"}, mediaType: MediaTypeTv, strict: true, year: 2010, season: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withDetail = %+v, want %+v", got, want)
+	}
+}
+
+func TestDedupNames(t *testing.T) {
+	got := dedupNames([]string{"Inception", "", "inception", "INCEPTION!", "盗梦空间", "  "})
+	if want := []string{"Inception", "盗梦空间"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupNames = %q, want %q", got, want)
+	}
+}
+
+func TestDateYear(t *testing.T) {
+	tests := map[string]int{"2010-07-16": 2010, "2010": 2010, "201": 0, "": 0, "abcd-01-01": 0}
+	for date, want := range tests {
+		if got := dateYear(date); got != want {
+			t.Errorf("dateYear(%q) = %d, want %d", date, got, want)
+		}
+	}
+}
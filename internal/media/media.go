@@ -1,7 +1,11 @@
 package media
 
 import (
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
 	"path"
+	"strconv"
 	"strings"
 )
 
@@ -25,6 +29,16 @@ var (
 		".tp"}
 )
 
+func MediaTypeName(t int) string {
+	switch t {
+	case MediaTypeMovie:
+		return "movie"
+	case MediaTypeTv:
+		return "tv"
+	}
+	return "unknown"
+}
+
 func IsMediaFile(f string) bool {
 	ext := strings.ToLower(path.Ext(f))
 	for _, e := range Ext {
@@ -35,15 +49,216 @@ func IsMediaFile(f string) bool {
 	return false
 }
 
+var defaultMedia *Media
+
+// InitMedia 设置全局媒体识别
+func InitMedia(m *Media) {
+	defaultMedia = m
+}
+
+// GetMedia 返回全局媒体识别，没有可用的元数据来源时为 nil
+func GetMedia() *Media {
+	return defaultMedia
+}
+
 type Media struct {
-	tmdb *Tmdb
+	providers []Provider // 按优先级排列
+	tmdb      *Tmdb      // 季集信息只来自 TMDB
 }
 
-func (m *Media) GetMediaInfo(title string, subtitle string) MetaInfo {
-	var media MetaInfo
+func NewMedia(providers ...Provider) *Media {
+	m := &Media{providers: providers}
+	for _, p := range providers {
+		if t, ok := p.(*Tmdb); ok {
+			m.tmdb = t
+			break
+		}
+	}
+	return m
+}
+
+func (m *Media) Tmdb() *Tmdb {
+	return m.tmdb
+}
+
+// GetEpisodes 已识别剧集对应的 TMDB 集，有播出日期时按日期查找，没有集数时返回整季
+func (m *Media) GetEpisodes(meta MetaInfo) ([]*Episode, error) {
 	if m.tmdb == nil {
+		return nil, ErrTmdbNotConfigured
+	}
+	info := meta.GetMeta()
+	if info.TmdbId == 0 || info.MediaType != MediaTypeTv {
+		return nil, ErrMediaNotFound
+	}
+	number := info.BeginSeason
+	if number == 0 && !info.IsSpecial {
+		number = 1
+	}
+	season, err := m.tmdb.GetSeasonDetail(info.TmdbId, number)
+	if err != nil {
+		return nil, err
+	}
+	if info.AirDate != "" {
+		return season.FindByAirDate(info.AirDate), nil
+	}
+	if info.BeginEpisode == 0 {
+		return season.Episodes, nil
+	}
+	end := info.EndEpisode
+	if end < info.BeginEpisode {
+		end = info.BeginEpisode
+	}
+	episodes := make([]*Episode, 0, end-info.BeginEpisode+1)
+	for i := info.BeginEpisode; i <= end; i++ {
+		if e := season.GetEpisode(i); e != nil {
+			episodes = append(episodes, e)
+		}
+	}
+	return episodes, nil
+}
+
+// GetMediaInfo 识别标题并从元数据来源补充媒体信息
+func (m *Media) GetMediaInfo(title string, subtitle string) (MetaInfo, error) {
+	meta := NewMeta(title, subtitle, MediaUnknown, IsMediaFile(title))
+	if meta == nil {
+		return nil, ErrMediaNotFound
+	}
+	if err := m.Recognize(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Recognize 按优先级依次查询元数据来源，合并详情后补充 meta。
+// 标题中带 ID 时优先按 ID 获取详情，只有 IMDb、TVDB ID 时先通过 TMDB find 找到 TMDB ID。
+// 第一个匹配的来源确定类型和标准标题，后续来源有对应 ID 时直接获取详情，否则用标准标题搜索。
+// 全部来源都没有结果时，有来源请求失败则返回失败原因，否则返回 ErrMediaNotFound
+func (m *Media) Recognize(meta MetaInfo) error {
+	if len(m.providers) == 0 {
+		return ErrNoProvider
+	}
+	info := meta.GetMeta()
+	query := newMatchQuery(info)
+	ids := ExternalIds{TmdbId: info.TmdbId, ImdbId: info.ImdbId, TvdbId: info.TvdbId, DoubanId: info.DoubanId}
+	if m.tmdb != nil && ids.TmdbId == 0 && (ids.ImdbId != "" || ids.TvdbId != 0) {
+		if r := m.findTmdb(ids, info.MediaType); r != nil {
+			ids.TmdbId, _ = strconv.Atoi(r.Id)
+			query.mediaType = r.MediaType
+			query.strict = true
+		}
+	}
+	if len(query.names) == 0 && ids == (ExternalIds{}) {
+		return ErrMediaNotFound
+	}
+	details := make([]*MediaDetail, 0, len(m.providers))
+	var failed error
+	for _, p := range m.providers {
+		detail, err := m.identify(p, query, ids)
+		if err != nil {
+			if !errors.Is(err, ErrMediaNotFound) {
+				log.Errorf("recognize %s from %s failed, %s", info.GetName(), p.Name(), err.Error())
+				failed = fmt.Errorf("%s: %w", p.Name(), err)
+			}
+			continue
+		}
+		if len(details) == 0 {
+			query = query.withDetail(detail)
+		}
+		// 来源自己的 ID 以详情为准，标题中的 ID 错误时已改为搜索
+		ids.Set(p.Name(), detail.Id)
+		ids.Merge(detail.Ids)
+		details = append(details, detail)
+	}
+	if len(details) == 0 {
+		if failed != nil {
+			return failed
+		}
+		return ErrMediaNotFound
+	}
+	fillMeta(info, mergeDetails(details), ids)
+	info.Category = Classify(info)
+	return nil
+}
+
+// identify 已知来源 ID 时直接获取详情，失败或没有 ID 时搜索并取得分最高的结果
+func (m *Media) identify(p Provider, query *matchQuery, ids ExternalIds) (*MediaDetail, error) {
+	if id := ids.Get(p.Name()); id != "" {
+		detail, err := getDetailById(p, id, query)
+		if err == nil {
+			return detail, nil
+		}
+		log.Warnf("get %s %s failed, fallback to search, %s", p.Name(), id, err.Error())
+	}
+	if len(query.names) == 0 {
+		return nil, ErrMediaNotFound
+	}
+	best, err := query.best(p)
+	if err != nil {
+		return nil, err
+	}
+	return getDetail(p, best.Id, best.MediaType)
+}
+
+// getDetailById 类型未确定时先按识别的类型获取，失败再尝试另一种类型
+func getDetailById(p Provider, id string, query *matchQuery) (*MediaDetail, error) {
+	detail, err := getDetail(p, id, query.mediaType)
+	if err == nil || query.strict {
+		return detail, err
+	}
+	other := MediaTypeTv
+	if query.mediaType == MediaTypeTv {
+		other = MediaTypeMovie
+	}
+	if detail, otherErr := getDetail(p, id, other); otherErr == nil {
+		return detail, nil
+	}
+	return nil, err
+}
+
+// findTmdb 通过 IMDb、TVDB ID 查找 TMDB 条目，优先选择与识别类型一致的结果
+func (m *Media) findTmdb(ids ExternalIds, mediaType int) *SearchResult {
+	var results []*SearchResult
+	var err error
+	if ids.ImdbId != "" {
+		results, err = m.tmdb.FindByImdbId(ids.ImdbId)
+	}
+	if err == nil && len(results) == 0 && ids.TvdbId != 0 {
+		results, err = m.tmdb.FindByTvdbId(ids.TvdbId)
+	}
+	if err != nil {
+		log.Errorf("find tmdb by external id failed, %s", err.Error())
 		return nil
 	}
+	for _, r := range results {
+		if r.MediaType == mediaType {
+			return r
+		}
+	}
+	if len(results) > 0 {
+		return results[0]
+	}
+	return nil
+}
 
-	return media
+// fillMeta 用合并后的详情填充 ID、标题、年份、类型、简介和评分
+func fillMeta(info *Meta, detail *MediaDetail, ids ExternalIds) {
+	info.TmdbId = ids.TmdbId
+	info.ImdbId = ids.ImdbId
+	info.TvdbId = ids.TvdbId
+	info.DoubanId = ids.DoubanId
+	info.MediaType = detail.MediaType
+	info.Title = detail.Title
+	info.ReleaseDate = detail.ReleaseDate
+	info.Runtime = detail.Runtime
+	info.Genres = detail.Genres
+	info.GenreIds = detail.GenreIds
+	info.OriginalLanguage = detail.Language
+	info.Countries = detail.Countries
+	info.Overview = detail.Overview
+	info.Rating = detail.Rating
+	if detail.Year != 0 {
+		info.Year = detail.Year
+	} else if year := dateYear(detail.ReleaseDate); year != 0 {
+		info.Year = year
+	}
 }
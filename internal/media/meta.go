@@ -26,23 +26,33 @@ var (
 		`|CD[\s.]*[1-9]|DVD[\s.]*[1-9]|DISK[\s.]*[1-9]|DISC[\s.]*[1-9]` +
 		`|[248]K|\d{3,4}[PIX]+` +
 		`|CD[\s.]*[1-9]|DVD[\s.]*[1-9]|DISK[\s.]*[1-9]|DISC[\s.]*[1-9]`)
-	NameStripYear         = regexp.MustCompile(`([\s.]+)(\d{4})-(\d{4})`)
-	NameStripSize         = regexp2.MustCompile(`[0-9.]+\s*[MGT]i?B(?![A-Z]+)`, regexp2.IgnoreCase)
-	NameStripYearMonthDay = regexp.MustCompile(`\d{4}[\s._-]\d{1,2}[\s._-]\d{1,2}`)
-	RomanNumerals         = regexp.MustCompile(`^M*(C[MD]|D?C{0,3})(X[CL]|L?X{0,3})(I[XV]|V?I{0,3})$`)
-	EpisodeRe             = regexp.MustCompile(`(?i)EP?(\d{2,4})$|^EP?(\d{1,4})$|^S\d{1,2}EP?(\d{1,4})$|S\d{2}EP?(\d{2,4})`)
-	SeasonRe              = regexp.MustCompile(`(?i)S(\d{2})|^S(\d{1,2})$|S(\d{1,2})E`)
+	NameStripYear    = regexp.MustCompile(`([\s.]+)(\d{4})-(\d{4})`)
+	NameStripSize    = regexp2.MustCompile(`(?<![0-9A-Za-z])\d+(?:\.\d+)?\s*[KMGT]i?B(?![A-Z]+)`, regexp2.IgnoreCase)
+	SizeRe           = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([KMGT])i?B$`)
+	AirDateRe        = regexp.MustCompile(`^第?((?:19|20)\d{2})(\d{2})(\d{2})期?$`)
+	AirDateCnRe      = regexp.MustCompile(`^((?:19|20)\d{2})年(\d{1,2})月(\d{1,2})日`)
+	RomanNumerals    = regexp.MustCompile(`^M*(C[MD]|D?C{0,3})(X[CL]|L?X{0,3})(I[XV]|V?I{0,3})$`)
+	EpisodeRe        = regexp.MustCompile(`(?i)EP?(\d{2,4})$|^EP?(\d{1,4})$|^S\d{1,2}EP?(\d{1,4})$|S\d{2}EP?(\d{2,4})`)
+	SeasonRe         = regexp.MustCompile(`(?i)S(\d{2})|^S(\d{1,2})$|S(\d{1,2})E`)
+	SeasonTokenRe    = regexp.MustCompile(`(?i)^S\d{1,2}(?:EP?\d{1,4})*$`)
+	EpisodeMultiRe   = regexp.MustCompile(`(?i)^(?:S\d{1,2})?EP?(\d{1,4})(?:-?EP?\d{1,4})*-?EP?(\d{1,4})$`)
+	SeasonEpisodeXRe = regexp.MustCompile(`(?i)^(\d{1,2})x(\d{2,4})$`)
+	SpecialRe        = regexp.MustCompile(`(?i)^(SPs?|OVA|OAD|Specials?|特别篇|番外篇?)(\d{1,3})?$`)
+	SeasonSuffixRe   = regexp.MustCompile(`(?i)SEASON$`)
+	SubtitleHintRe   = regexp.MustCompile(`[全第季集话話期]`)
+	DIYRe            = regexp.MustCompile(`D[Ii]Y`)
+	DIYGroupRe       = regexp.MustCompile(`-D[Ii]Y@`)
 
-	SourceExp       = `(?i)^BLURAY$|^HDTV$|^UHDTV$|^HDDVD$|^WEBRIP$|^DVDRIP$|^BDRIP$|^BLU$|^WEB$|^BD$|^HDRip$`
-	EffectExp       = `^REMUX$|^UHD$|^SDR$|^HDR\d*$|^DOLBY$|^DOVI$|^DV$|^3D$|^REPACK$`
+	SourceExp       = `(?i)^(?:BLURAY|HDTV|UHDTV|HDDVD|WEBRIP|DVDRIP|BDRIP|BLU|WEB|BD|HDRip)$`
+	EffectExp       = `^(?:REMUX|UHD|SDR|HDR\d*|DOLBY|DOVI|DV|3D|REPACK)$`
 	SourceRe        = regexp.MustCompile(SourceExp)
 	EffectRe        = regexp.MustCompile(EffectExp)
-	ResourcesTypeRe = regexp.MustCompile(fmt.Sprintf("(?i)%s|%s", SourceExp, SourceExp))
+	ResourcesTypeRe = regexp.MustCompile(SourceExp)
 	ResourcesPixRe  = regexp.MustCompile(`(?i)^[SBUHD]*(\d{3,4}[PI]+)|\d{3,4}X(\d{3,4})`)
 	ResourcesPixRe2 = regexp.MustCompile(`(?i)(^[248]+K)`)
-	VideoEncodeRe   = regexp.MustCompile(`(?i)^[HX]26[45]$|^AVC$|^HEVC$|^VC\d?$|^MPEG\d?$|^Xvid$|^DivX$|^HDR\d*$`)
-	AudioEncodeRe   = regexp.MustCompile(`(?i)^DTS\d?$|^DTSHD$|^DTSHDMA$|^Atmos$|^TrueHD\d?$|^AC3$|^\dAudios?$|^DDP\d?$|^DD\d?$|^LPCM\d?$|^AAC\d?$|^FLAC\d?$|^HD\d?$|^MA\d?$`)
-	PartRe          = regexp.MustCompile(`(?i)(^PART[0-9ABI]{0,2}$|^CD[0-9]{0,2}$|^DVD[0-9]{0,2}$|^DISK[0-9]{0,2}$|^DISC[0-9]{0,2}$)`)
+	VideoEncodeRe   = regexp.MustCompile(`(?i)^(?:[HX]26[45]|AVC|HEVC|VC\d?|MPEG\d?|Xvid|DivX|HDR\d*)$`)
+	AudioEncodeRe   = regexp.MustCompile(`(?i)^(?:DTS\d?|DTSHD|DTSHDMA|Atmos|TrueHD\d?|AC3|\dAudios?|DDP\d?|DD\d?|LPCM\d?|AAC\d?|FLAC\d?|HD\d?|MA\d?)$`)
+	PartRe          = regexp.MustCompile(`(?i)^(?:PART[0-9ABI]{0,2}|CD[0-9]{0,2}|DVD[0-9]{0,2}|DISK[0-9]{0,2}|DISC[0-9]{0,2})$`)
 
 	SubtitleSeasonRe     = regexp2.MustCompile(`(?<![全|共]\s*)[第\s]+([0-9一二三四五六七八九十S\-]+)\s*季(?!\s*[全|共])`, regexp2.IgnoreCase)
 	SubtitleSeasonAllRe  = regexp2.MustCompile(`[全|共]\s*([0-9一二三四五六七八九十]+)\s*季|([0-9一二三四五六七八九十]+)\s*季\s*[全|共]`, regexp2.IgnoreCase)
@@ -56,40 +66,57 @@ type MetaInfo interface {
 }
 
 type Meta struct {
-	OrgString      string   //原字符串
-	RevString      string   // 识别词处理后字符串
-	OrgTitle       string   // 原标题
-	Title          string   // 媒体标题
-	Subtitle       string   // 副标题
-	MediaType      int      // 类型 电影、电视剧
-	CnName         string   // 中文名
-	EnName         string   // 英文名
-	TotalSeasons   int      // 总季数
-	BeginSeason    int      // 识别的开始季 数字
-	EndSeason      int      // 识别的结束季 数字
-	TotalEpisodes  int      // 总集数
-	BeginEpisode   int      // 识别的开始集
-	EndEpisode     int      // 识别的结束集
-	Category       string   // 二级分类
-	TmdbId         int      // TMDB ID
-	ImdbId         string   // IMDB ID
-	TvdbId         int      // TVDB ID
-	DoubanId       int      // 豆瓣 ID
-	Keyword        []string // 自定义搜索词
-	ReleaseDate    string   // 媒体发行日期
-	Runtime        int      // 播放时长
-	Year           int      // 媒体年份
-	ResourcePix    string   // 分辨率
-	ResourceType   string   // 来源
-	ResourceEffect string   // 特效
-	VideoEncode    string   // 视频编码
-	AudioEncode    string   // 音频编码
-	Part           string
-	ReplacedWords  []string // 识别辅助 替换词
-	IgnoredWords   []string // 识别辅助 忽略词
-	OffsetWords    []string // 识别辅助 集偏移词
-	tokens         *utils.Tokenizer
-	IsFile         bool
+	OrgString         string   //原字符串
+	RevString         string   // 识别词处理后字符串
+	OrgTitle          string   // 原标题
+	Title             string   // 媒体标题
+	Subtitle          string   // 副标题
+	MediaType         int      // 类型 电影、电视剧
+	CnName            string   // 中文名
+	EnName            string   // 英文名
+	JpName            string   // 日文名
+	KrName            string   // 韩文名
+	TotalSeasons      int      // 总季数
+	BeginSeason       int      // 识别的开始季 数字
+	EndSeason         int      // 识别的结束季 数字
+	TotalEpisodes     int      // 总集数
+	BeginEpisode      int      // 识别的开始集
+	EndEpisode        int      // 识别的结束集
+	IsSpecial         bool     // 特别篇，归入 Season 00
+	SpecialType       string   // 特别篇类型 SP、OVA、OAD、Special
+	Category          string   // 二级分类
+	TmdbId            int      // TMDB ID
+	ImdbId            string   // IMDB ID
+	TvdbId            int      // TVDB ID
+	DoubanId          int      // 豆瓣 ID
+	Keyword           []string // 自定义搜索词
+	ReleaseDate       string   // 媒体发行日期
+	Genres            []string // 媒体类型，如 剧情、动画
+	GenreIds          []int    // TMDB 类型 ID
+	OriginalLanguage  string   // 原始语言，ISO 639-1
+	Countries         []string // 出品国家，ISO 3166-1
+	Overview          string   // 简介
+	Rating            float32  // 评分
+	AirDate           string   // 识别的播出日期 yyyy-mm-dd，日播节目、综艺以此代替集
+	Runtime           int      // 播放时长
+	Size              int64    // 资源大小，字节
+	Year              int      // 媒体年份
+	ResourcePix       string   // 分辨率
+	ResourceType      string   // 来源
+	ResourceEffect    string   // 特效
+	VideoEncode       string   // 视频编码
+	AudioEncode       string   // 音频编码
+	Part              string
+	Edition           string   // 版本，如 Director's Cut、Extended、IMAX
+	ReleaseGroup      string   // 发布组、字幕组
+	AudioLanguages    []string // 音轨语言，ISO 代码
+	SubtitleLanguages []string // 字幕语言
+	ReplacedWords     []string // 识别辅助 替换词
+	IgnoredWords      []string // 识别辅助 忽略词
+	OffsetWords       []string // 识别辅助 集偏移词
+	tokens            *utils.Tokenizer
+	trace             *Trace
+	IsFile            bool
 }
 
 func (m *Meta) GetMeta() *Meta {
@@ -101,6 +128,10 @@ func (m *Meta) GetName() string {
 		return m.CnName
 	} else if m.EnName != "" {
 		return m.EnName
+	} else if m.JpName != "" {
+		return m.JpName
+	} else if m.KrName != "" {
+		return m.KrName
 	} else {
 		return m.CnName
 	}
@@ -123,16 +154,76 @@ func (m *Meta) GetTitle() string {
 	return ""
 }
 
+// GetSeasonString 季的字符串表示，如 S01、S01-S03
+func (m *Meta) GetSeasonString() string {
+	if m.BeginSeason == 0 {
+		if m.IsSpecial {
+			return "S00"
+		}
+		return ""
+	}
+	if m.EndSeason > m.BeginSeason {
+		return fmt.Sprintf("S%02d-S%02d", m.BeginSeason, m.EndSeason)
+	}
+	return fmt.Sprintf("S%02d", m.BeginSeason)
+}
+
+// GetEpisodeString 集的字符串表示，如 E05、E01-E12
+func (m *Meta) GetEpisodeString() string {
+	if m.BeginEpisode == 0 {
+		return ""
+	}
+	if m.EndEpisode > m.BeginEpisode {
+		return fmt.Sprintf("E%02d-E%02d", m.BeginEpisode, m.EndEpisode)
+	}
+	return fmt.Sprintf("E%02d", m.BeginEpisode)
+}
+
+// ParseSize 解析 23.5GB、700MB 这样的大小，按 1024 进制换算为字节
+func ParseSize(text string) int64 {
+	match := SizeRe.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0
+	}
+	size, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToUpper(match[2]) {
+	case "T":
+		size *= 1 << 40
+	case "G":
+		size *= 1 << 30
+	case "M":
+		size *= 1 << 20
+	case "K":
+		size *= 1 << 10
+	}
+	return int64(size)
+}
+
+// GetSizePerMinute 每分钟的大小，用于比较码率，大小或时长未知时返回 0
+func (m *Meta) GetSizePerMinute() int64 {
+	if m.Size <= 0 || m.Runtime <= 0 {
+		return 0
+	}
+	return m.Size / int64(m.Runtime)
+}
+
 type MetaVideo struct {
 	*Meta
 }
 
 func NewMetaVideo(title, subtitle string, isFile bool) *MetaVideo {
+	return newMetaVideo(title, subtitle, isFile, nil)
+}
+
+func newMetaVideo(title, subtitle string, isFile bool, trace *Trace) *MetaVideo {
 	if title == "" {
 		return nil
 	}
 	self := &MetaVideo{
-		Meta: &Meta{OrgTitle: title, OrgString: title, Subtitle: subtitle, IsFile: isFile},
+		Meta: &Meta{OrgTitle: title, OrgString: title, Subtitle: subtitle, IsFile: isFile, trace: trace},
 	}
 	// 判断是否纯数字命名的文件
 	name := utils.GetFileName(title)
@@ -141,37 +232,46 @@ func NewMetaVideo(title, subtitle string, isFile bool) *MetaVideo {
 		self.Meta.MediaType = MediaTypeTv
 		return self
 	}
-	// 去掉名称中第1个[]的内容
+	// 识别发布组，并从标题中去掉，避免混入名称
+	self.ReleaseGroup, title = MatchReleaseGroup(title)
+	// 去掉名称中第1个[]的内容，其中的语言标签和版本保留
+	begin := NameNoBeginRe.FindString(title)
+	self.parseLanguages(begin)
+	if begin != "" {
+		self.Edition = MatchEdition(strings.TrimSpace(begin[1 : len(begin)-1]))
+	}
 	title = NameNoBeginRe.ReplaceAllString(title, "")
 	// 把xxxx-xxxx年份换成前一个年份，常出现在季集上
 	title = NameStripYear.ReplaceAllString(title, "$1$2")
-	// 把大小去掉
+	// 识别大小并从标题中去掉
+	if match, _ := NameStripSize.FindStringMatch(title); match != nil {
+		self.Size = ParseSize(match.String())
+	}
 	title = utils.ReplaceString(NameStripSize, title, "")
-	// 把年月日去掉
-	title = NameStripYearMonthDay.ReplaceAllString(title, "")
 	self.parseTitle(title)
 	return self
 }
 
 func (m *MetaVideo) parseTitle(title string) {
+	if m.trace != nil {
+		m.trace.Title = title
+	}
 	tokens := utils.NewToken(title)
-	p := NewParser(
-		tokens,
-		NewParsePart(m.GetMeta()),
-		NewParseName(m.GetMeta()),
-		NewParseYear(m.GetMeta()),
-		NewParseResourcePix(m.GetMeta()),
-		NewParseSeason(m.GetMeta()),
-		NewParseEpisode(m.GetMeta()),
-		NewParseResourceType(m.GetMeta()),
-		NewParseVideoEncode(m.GetMeta()),
-		NewParseAudioEncode(m.GetMeta()),
-	)
+	p := NewParser(tokens, NewSteps(m.GetMeta())...)
+	if m.trace != nil {
+		p.SetTrace(m.trace, m.GetMeta())
+	}
 	token, err := tokens.Cur()
 	for err == nil {
 		p.Run(token)
 		token, err = tokens.Next()
 	}
+	if m.trace != nil {
+		before := metaFields(m.GetMeta())
+		defer func() {
+			m.trace.Finish = diffFields(before, metaFields(m.GetMeta()))
+		}()
+	}
 	if len(p.Effect) > 0 {
 		m.ResourceEffect = strings.Join(p.Effect, " ")
 	}
@@ -179,9 +279,7 @@ func (m *MetaVideo) parseTitle(title string) {
 		m.ResourceType = strings.TrimSpace(p.Source)
 	}
 	if strings.Contains(m.ResourceType, "BluRay") {
-		ok1, _ := regexp.MatchString(`D[Ii]Y`, m.Subtitle)
-		ok2, _ := regexp.MatchString(`-D[Ii]Y@`, m.OrgTitle)
-		if m.Subtitle != "" && ok1 || ok2 {
+		if m.Subtitle != "" && DIYRe.MatchString(m.Subtitle) || DIYGroupRe.MatchString(m.OrgTitle) {
 			m.ResourceType = fmt.Sprintf("%s DIY", m.ResourceType)
 		}
 	}
@@ -191,16 +289,24 @@ func (m *MetaVideo) parseTitle(title string) {
 			m.parseSubtitle(m.Subtitle)
 		}
 	}
+	m.parseLanguages(m.Subtitle)
 
 	// 默认为电影
 	if m.MediaType == MediaTypeUnknown {
 		m.MediaType = MediaTypeMovie
 	}
+	m.CnName, m.EnName = trimName(m.CnName), trimName(m.EnName)
+	m.JpName, m.KrName = trimName(m.JpName), trimName(m.KrName)
 	if strings.ToUpper(m.Part) == "PART" {
 		m.Part = ""
 	}
 }
 
+// trimName 去掉名称两端和连续的空白
+func trimName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
 func (m *MetaVideo) parseSubtitle(title string) bool {
 	find := false
 	if title == "" {
@@ -208,7 +314,7 @@ func (m *MetaVideo) parseSubtitle(title string) bool {
 	}
 
 	title = fmt.Sprintf(" %s ", title)
-	if ok, _ := regexp.MatchString(`[全第季集话話期]`, title); ok {
+	if SubtitleHintRe.MatchString(title) {
 		//第x季
 		if match, _ := SubtitleSeasonRe.FindStringMatch(title); match != nil {
 			season := match.Groups()[1].Capture.String()
@@ -240,6 +346,16 @@ func (m *MetaVideo) parseSubtitle(title string) bool {
 			episode := strings.ToUpper(match.Groups()[1].Capture.String())
 			episode = strings.Replace(episode, "E", "", -1)
 			episode = strings.TrimSpace(strings.Replace(episode, "P", "", -1))
+			// 第20231014期
+			if match := AirDateRe.FindStringSubmatch(episode); match != nil {
+				if date, ok := makeAirDate(match[1], match[2], match[3]); ok {
+					if m.AirDate == "" {
+						m.AirDate = date
+					}
+					m.MediaType = MediaTypeTv
+					return true
+				}
+			}
 			episodes := strings.Split(episode, "-")
 			var beginEpisode, endEpisode int
 			if len(episodes) == 2 {
@@ -293,17 +409,21 @@ func (m *MetaVideo) parseSubtitle(title string) bool {
 	return find
 }
 
-type MetaAnim struct {
-	*Meta
+func NewMeta(title, subtitle string, mediaType int, isFile bool) MetaInfo {
+	return newMeta(title, subtitle, mediaType, isFile, nil)
 }
 
-func NewMetaAnim(title, subtitle string) *MetaAnim {
-	return &MetaAnim{
-		Meta: &Meta{Title: title, Subtitle: subtitle},
+// ExplainMeta 识别标题并返回解析过程
+func ExplainMeta(title, subtitle string, mediaType int, isFile bool) (MetaInfo, *Trace) {
+	trace := NewTrace()
+	meta := newMeta(title, subtitle, mediaType, isFile, trace)
+	if meta == nil {
+		return nil, nil
 	}
+	return meta, trace
 }
 
-func NewMeta(title, subtitle string, mediaType int, isFile bool) MetaInfo {
+func newMeta(title, subtitle string, mediaType int, isFile bool, trace *Trace) MetaInfo {
 	if title == "" {
 		return nil
 	}
@@ -316,20 +436,36 @@ func NewMeta(title, subtitle string, mediaType int, isFile bool) MetaInfo {
 		log.Warnf("process title failed, %s", err.Error())
 	}
 	subtitle, _, _ = utils.ProcessTitle(subtitle)
+	// 标题和副标题中的 {tmdb-12345}、[imdbid-tt0111161] 等 ID 标签
+	ids, title := ParseExternalIds(title)
+	subIds, subtitle := ParseExternalIds(subtitle)
+	ids.Merge(subIds)
+	if title == "" {
+		return nil
+	}
+
+	if trace != nil {
+		trace.Input = orgTitle
+		trace.Revised = title
+	}
 
 	if mediaType == MediaAnim || IsAnim(title) {
-		meta = NewMetaAnim(title, subtitle)
+		meta = NewMetaAnim(title, subtitle, isFile)
+		if trace != nil {
+			trace.Parser = "anim"
+			trace.Finish = diffFields(metaFields(&Meta{}), metaFields(meta.GetMeta()))
+		}
 	} else {
-		meta = NewMetaVideo(title, subtitle, isFile)
+		meta = newMetaVideo(title, subtitle, isFile, trace)
+		if trace != nil {
+			trace.Parser = "video"
+		}
 	}
 	meta.GetMeta().OrgString = orgTitle
 	meta.GetMeta().RevString = title
 	meta.GetMeta().IgnoredWords = info.Ignored
 	meta.GetMeta().ReplacedWords = info.Replaced
 	meta.GetMeta().OffsetWords = info.Offset
+	meta.GetMeta().setIds(ids)
 	return meta
 }
-
-func IsAnim(title string) bool {
-	return false
-}
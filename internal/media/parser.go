@@ -3,9 +3,9 @@ package media
 import (
 	"fmt"
 	"mediahub/internal/utils"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -27,6 +27,9 @@ const (
 	ParseStateEffect
 	ParseStateVideoEncode
 	ParseStateAudioEncode
+	ParseStateJP
+	ParseStateKR
+	ParseStateAirDate
 )
 
 func CheckFlag(f1, f2 int) bool {
@@ -67,6 +70,8 @@ type Parser struct {
 	lastToken  string
 	Source     string
 	Effect     []string
+	trace      *Trace
+	meta       *Meta
 }
 
 func NewParser(token *utils.Tokenizer, step ...Step) *Parser {
@@ -79,11 +84,27 @@ func NewParser(token *utils.Tokenizer, step ...Step) *Parser {
 	return p
 }
 
+// SetTrace 记录每个 token 经过的 Step 和 meta 的变化
+func (p *Parser) SetTrace(trace *Trace, meta *Meta) {
+	p.trace = trace
+	p.meta = meta
+}
+
 func (p *Parser) Run(token string) {
+	var tt *TraceToken
+	if p.trace != nil {
+		tt = p.trace.beginToken(p.token.Index(), token)
+	}
 	for _, s := range p.steps {
 		if !s.Complete() {
+			next := false
+			if tt != nil {
+				next = p.trace.runStep(tt, p, s, token, p.meta)
+			} else {
+				next = s.Run(p, token)
+			}
 			// 是否需要继续
-			if !s.Run(p, token) {
+			if !next {
 				break
 			}
 		}
@@ -128,7 +149,7 @@ func (p *ParseYear) Run(ctx *Parser, token string) bool {
 		} else if p.meta.CnName != "" {
 			p.meta.CnName = fmt.Sprintf("%s %s", p.meta.CnName, token)
 		}
-	} else if ok, _ := regexp.MatchString(`(?i)SEASON$`, p.meta.EnName); ok && p.meta.EnName != "" {
+	} else if SeasonSuffixRe.MatchString(p.meta.EnName) && p.meta.EnName != "" {
 		// 如果匹配到年，且英文名结尾为Season，说明Season属于标题，不应在后续作为干扰词去除
 		p.meta.EnName += " "
 	}
@@ -191,7 +212,14 @@ func NewParseName(m *Meta) Step {
 
 func (p *ParseName) Run(ctx *Parser, token string) bool {
 	if ctx.unknown != "" {
-		if p.meta.CnName == "" {
+		if p.absoluteEpisode(ctx.unknown) {
+			// One.Piece.1071.1080p 名称后不是年份的 4 位数字为绝对集数
+			p.meta.BeginEpisode, _ = strconv.Atoi(ctx.unknown)
+			p.meta.TotalEpisodes = 1
+			p.meta.MediaType = MediaTypeTv
+			ctx.tokenState = ParseStateEpisode
+			ctx.SetFlag(ParseFlagName)
+		} else if p.meta.CnName == "" && p.meta.JpName == "" && p.meta.KrName == "" {
 			if p.meta.EnName == "" {
 				p.meta.EnName = ctx.unknown
 			} else if ctx.unknown != strconv.Itoa(p.meta.Year) {
@@ -213,6 +241,32 @@ func (p *ParseName) Run(ctx *Parser, token string) bool {
 		ctx.tokenState = ParseSeasonEpisode
 		return true
 	}
+	// 特别篇 SP、OVA 等不属于名称
+	if p.meta.GetName() != "" {
+		if _, _, ok := matchSpecial(ctx, token); ok {
+			ctx.SetFlag(ParseFlagName)
+			return true
+		}
+	}
+	// 日文名中可能夹杂纯汉字的词，跟在日文后的汉字仍属于日文名
+	if utils.IsJapanese(token) || ctx.tokenState == ParseStateJP && utils.IsChinese(token) {
+		ctx.tokenState = ParseStateJP
+		if p.meta.JpName == "" {
+			p.meta.JpName = token
+		} else {
+			p.meta.JpName = fmt.Sprintf("%s %s", p.meta.JpName, token)
+		}
+		return true
+	}
+	if utils.IsKorean(token) {
+		ctx.tokenState = ParseStateKR
+		if p.meta.KrName == "" {
+			p.meta.KrName = token
+		} else {
+			p.meta.KrName = fmt.Sprintf("%s %s", p.meta.KrName, token)
+		}
+		return true
+	}
 	if utils.IsChinese(token) {
 		ctx.tokenState = ParseStateCN
 		if p.meta.CnName == "" {
@@ -238,9 +292,9 @@ func (p *ParseName) Run(ctx *Parser, token string) bool {
 				if strings.HasPrefix(token, "0") {
 					return true
 				}
-				// 中文名后面跟的数字不是年份的极有可能是集
+				// 中文、日文、韩文名后面跟的数字不是年份的极有可能是集
 				if isNumber {
-					if ctx.tokenState == ParseStateCN {
+					if ctx.tokenState == ParseStateCN || ctx.tokenState == ParseStateJP || ctx.tokenState == ParseStateKR {
 						val, _ := strconv.Atoi(token)
 						if val < 1900 {
 							return true
@@ -251,6 +305,10 @@ func (p *ParseName) Run(ctx *Parser, token string) bool {
 					// 4位以下的数字或者罗马数字，拼装到已有标题中
 					if ctx.tokenState == ParseStateCN {
 						p.meta.CnName = fmt.Sprintf("%s %s", p.meta.CnName, token)
+					} else if ctx.tokenState == ParseStateJP {
+						p.meta.JpName = fmt.Sprintf("%s %s", p.meta.JpName, token)
+					} else if ctx.tokenState == ParseStateKR {
+						p.meta.KrName = fmt.Sprintf("%s %s", p.meta.KrName, token)
 					} else if ctx.tokenState == ParseStateEN {
 						p.meta.EnName = fmt.Sprintf("%s %s", p.meta.EnName, token)
 					}
@@ -270,18 +328,20 @@ func (p *ParseName) Run(ctx *Parser, token string) bool {
 			}
 		} else if NameSeWords.MatchString(token) {
 			//# 如果匹配到季，英文名结尾为Season，说明Season属于标题，不应在后续作为干扰词去除
-			if ok, _ := regexp.MatchString(`(?i)SEASON$`, token); ok && p.meta.EnName != "" {
+			if SeasonSuffixRe.MatchString(token) && p.meta.EnName != "" {
 				p.meta.EnName += " "
 			}
 			ctx.SetFlag(ParseFlagName)
 		} else if EpisodeRe.MatchString(token) ||
+			SeasonTokenRe.MatchString(token) ||
+			SeasonEpisodeXRe.MatchString(token) ||
 			ResourcesTypeRe.MatchString(token) ||
 			ResourcesPixRe.MatchString(token) {
 			// 集、来源、版本等不要
 			ctx.SetFlag(ParseFlagName)
 		} else {
-			// 后缀名不要
-			if IsMediaFile(fmt.Sprintf("xxx.%s", token)) {
+			// 后缀名、分隔符留下的空 token 不要
+			if token == "" || IsMediaFile(fmt.Sprintf("xxx.%s", token)) {
 				return true
 			}
 			// 英文或者英文+数字，拼装起来
@@ -297,6 +357,75 @@ func (p *ParseName) Run(ctx *Parser, token string) bool {
 	return true
 }
 
+// absoluteEpisode 英文名后 4 位且不在年份范围内的数字，没有其他季集信息时为绝对集数，常见于长篇动漫
+func (p *ParseName) absoluteEpisode(token string) bool {
+	if len(token) != 4 || p.meta.EnName == "" || p.meta.CnName != "" ||
+		p.meta.BeginEpisode != 0 || p.meta.BeginSeason != 0 || p.meta.IsSpecial || p.meta.AirDate != "" {
+		return false
+	}
+	episode, err := strconv.Atoi(token)
+	return err == nil && (episode < 1900 || episode > 2050)
+}
+
+type ParseAirDate struct {
+	*ParseStep
+}
+
+func NewParseAirDate(m *Meta) Step {
+	return &ParseAirDate{
+		ParseStep: NewParseStep(m),
+	}
+}
+
+// Run 识别 2023.10.14、20231014、第20231014期、2023年10月14日 形式的播出日期
+func (p *ParseAirDate) Run(ctx *Parser, token string) bool {
+	if p.meta.AirDate != "" {
+		return true
+	}
+	var year, month, day string
+	skip := 0
+	if match := AirDateRe.FindStringSubmatch(token); match != nil {
+		year, month, day = match[1], match[2], match[3]
+	} else if match = AirDateCnRe.FindStringSubmatch(token); match != nil {
+		year, month, day = match[1], match[2], match[3]
+	} else if DigitRe.MatchString(token) && len(token) == 4 {
+		m, err1 := ctx.token.PeekN(1)
+		d, err2 := ctx.token.PeekN(2)
+		if err1 == nil && err2 == nil &&
+			DigitRe.MatchString(m) && len(m) <= 2 &&
+			DigitRe.MatchString(d) && len(d) <= 2 {
+			year, month, day = token, m, d
+			skip = 2
+		}
+	}
+	if year == "" {
+		return true
+	}
+	date, ok := makeAirDate(year, month, day)
+	if !ok {
+		return true
+	}
+	p.meta.AirDate = date
+	p.meta.MediaType = MediaTypeTv
+	for i := 0; i < skip; i++ {
+		ctx.token.Next()
+	}
+	ctx.tokenState = ParseStateAirDate
+	// 日期在名称之前时，名称还需要继续识别
+	if p.meta.GetName() != "" {
+		ctx.SetFlag(ParseFlagName)
+	}
+	return false
+}
+
+func makeAirDate(year, month, day string) (string, bool) {
+	t, err := time.Parse("2006-1-2", fmt.Sprintf("%s-%s-%s", year, strings.TrimPrefix(month, "0"), strings.TrimPrefix(day, "0")))
+	if err != nil || !isYear(t.Year()) {
+		return "", false
+	}
+	return t.Format("2006-01-02"), true
+}
+
 type ParseResourcePix struct {
 	*ParseStep
 }
@@ -349,6 +478,18 @@ func NewParseSeason(m *Meta) Step {
 }
 
 func (p *ParseSeason) Run(ctx *Parser, token string) bool {
+	// 1x05
+	if match := SeasonEpisodeXRe.FindStringSubmatch(token); match != nil {
+		if p.meta.BeginSeason == 0 {
+			p.meta.BeginSeason, _ = strconv.Atoi(match[1])
+			p.meta.TotalSeasons = 1
+		}
+		p.meta.IsSpecial = p.meta.IsSpecial || p.meta.BeginSeason == 0
+		p.meta.MediaType = MediaTypeTv
+		ctx.SetFlag(ParseFlagName)
+		ctx.tokenState = ParseStateSeason
+		return true
+	}
 	seasons := SeasonRe.FindStringSubmatch(token)
 	if len(seasons) > 0 {
 		for _, se := range seasons {
@@ -357,6 +498,10 @@ func (p *ParseSeason) Run(ctx *Parser, token string) bool {
 			}
 			if DigitRe.MatchString(se) {
 				se, _ := strconv.Atoi(se)
+				// S00 为特别篇
+				if se == 0 {
+					p.meta.IsSpecial = true
+				}
 				if p.meta.BeginSeason == 0 {
 					p.meta.BeginSeason = se
 					p.meta.TotalSeasons = 1
@@ -402,6 +547,50 @@ func NewParseEpisode(m *Meta) Step {
 }
 
 func (p *ParseEpisode) Run(ctx *Parser, token string) bool {
+	// S01E01E02、S01E01-E03
+	if match := EpisodeMultiRe.FindStringSubmatch(token); match != nil {
+		begin, _ := strconv.Atoi(match[1])
+		end, _ := strconv.Atoi(match[2])
+		if p.meta.BeginEpisode == 0 {
+			p.meta.BeginEpisode = begin
+			p.meta.TotalEpisodes = 1
+			if end > begin {
+				p.meta.EndEpisode = end
+				p.meta.TotalEpisodes = end - begin + 1
+			}
+		}
+		p.meta.MediaType = MediaTypeTv
+		ctx.SetFlag(ParseFlagName)
+		ctx.tokenState = ParseStateEpisode
+		return false
+	}
+	// 1x05
+	if match := SeasonEpisodeXRe.FindStringSubmatch(token); match != nil {
+		if p.meta.BeginEpisode == 0 {
+			p.meta.BeginEpisode, _ = strconv.Atoi(match[2])
+			p.meta.TotalEpisodes = 1
+		}
+		p.meta.MediaType = MediaTypeTv
+		ctx.tokenState = ParseStateEpisode
+		return false
+	}
+	// SP、OVA、OAD、Specials
+	if p.meta.GetName() != "" {
+		if typ, num, ok := matchSpecial(ctx, token); ok {
+			p.meta.IsSpecial = true
+			if p.meta.SpecialType == "" {
+				p.meta.SpecialType = typ
+			}
+			if num != "" && p.meta.BeginEpisode == 0 {
+				p.meta.BeginEpisode, _ = strconv.Atoi(num)
+				p.meta.TotalEpisodes = 1
+			}
+			p.meta.MediaType = MediaTypeTv
+			ctx.SetFlag(ParseFlagName)
+			ctx.tokenState = ParseStateEpisode
+			return false
+		}
+	}
 	episodes := EpisodeRe.FindStringSubmatch(token)
 	if len(episodes) > 0 {
 
@@ -470,6 +659,34 @@ func (p *ParseEpisode) Run(ctx *Parser, token string) bool {
 	return true
 }
 
+// matchSpecial 识别特别篇标记，单数的 Special 只在其后为数字、技术参数或结尾时识别，避免误伤标题
+func matchSpecial(ctx *Parser, token string) (typ string, num string, ok bool) {
+	match := SpecialRe.FindStringSubmatch(token)
+	if match == nil {
+		return "", "", false
+	}
+	if strings.EqualFold(match[1], "Special") && match[2] == "" {
+		next, err := ctx.token.Peek()
+		if err == nil && !DigitRe.MatchString(next) &&
+			!ResourcesPixRe.MatchString(next) && !ResourcesTypeRe.MatchString(next) &&
+			!IsMediaFile(fmt.Sprintf("xxx.%s", next)) {
+			return "", "", false
+		}
+	}
+	return SpecialType(match[1]), match[2], true
+}
+
+// SpecialType 统一特别篇类型名称
+func SpecialType(s string) string {
+	switch typ := strings.ToUpper(s); typ {
+	case "SP", "SPS":
+		return "SP"
+	case "OVA", "OAD":
+		return typ
+	}
+	return "Special"
+}
+
 type ParseResourceType struct {
 	*ParseStep
 }
@@ -0,0 +1,133 @@
+package media
+
+import "testing"
+
+func TestParseEpisodeNotation(t *testing.T) {
+	tests := []struct {
+		title       string
+		mediaType   int
+		name        string
+		season      int
+		begin       int
+		end         int
+		total       int
+		special     bool
+		specialType string
+	}{
+		{"Show.S01E01E02.1080p.WEB-DL", MediaTypeTv, "Show", 1, 1, 2, 2, false, ""},
+		{"Show.S01E01-E03.1080p", MediaTypeTv, "Show", 1, 1, 3, 3, false, ""},
+		{"Show.S02E05-06.720p", MediaTypeTv, "Show", 2, 5, 6, 2, false, ""},
+		{"Show.1x05.720p.HDTV", MediaTypeTv, "Show", 1, 5, 0, 1, false, ""},
+		{"Show.S00E03.1080p", MediaTypeTv, "Show", 0, 3, 0, 1, true, ""},
+		{"Show.SP.1080p", MediaTypeTv, "Show", 0, 0, 0, 0, true, "SP"},
+		{"Show.SP02.1080p", MediaTypeTv, "Show", 0, 2, 0, 1, true, "SP"},
+		{"Show.OVA.1080p", MediaTypeTv, "Show", 0, 0, 0, 0, true, "OVA"},
+		{"Show.OVA2.720p", MediaTypeTv, "Show", 0, 2, 0, 1, true, "OVA"},
+		{"Show.Special.1080p", MediaTypeTv, "Show", 0, 0, 0, 0, true, "Special"},
+		{"Show.Specials.1080p", MediaTypeTv, "Show", 0, 0, 0, 0, true, "Special"},
+		{"Special.Forces.2019.1080p", MediaTypeMovie, "Special Forces", 0, 0, 0, 0, false, ""},
+		// 长篇动漫的绝对集数
+		{"Show.E1071.1080p", MediaTypeTv, "Show", 0, 1071, 0, 1, false, ""},
+		{"One.Piece.1071.1080p.WEB", MediaTypeTv, "One Piece", 0, 1071, 0, 1, false, ""},
+		{"One Piece - 1071 [1080p]", MediaTypeTv, "One Piece", 0, 1071, 0, 1, false, ""},
+		{"Detective.Conan.1100.1080p", MediaTypeTv, "Detective Conan", 0, 1100, 0, 1, false, ""},
+		{"[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv", MediaTypeTv, "One Piece", 0, 1071, 0, 1, false, ""},
+		// 年份范围内的 4 位数字不是集
+		{"Show.2023.1080p", MediaTypeMovie, "Show", 0, 0, 0, 0, false, ""},
+		{"Apollo.13.1995.1080p", MediaTypeMovie, "Apollo 13", 0, 0, 0, 0, false, ""},
+	}
+	for _, tt := range tests {
+		meta := NewMeta(tt.title, "", MediaUnknown, IsMediaFile(tt.title))
+		if meta == nil {
+			t.Fatalf("NewMeta(%q) = nil", tt.title)
+		}
+		m := meta.GetMeta()
+		if m.MediaType != tt.mediaType || m.GetName() != tt.name || m.BeginSeason != tt.season ||
+			m.BeginEpisode != tt.begin || m.EndEpisode != tt.end || m.TotalEpisodes != tt.total ||
+			m.IsSpecial != tt.special || m.SpecialType != tt.specialType {
+			t.Errorf("%q: got type %d name %q season %d episodes %d-%d/%d special %v %q",
+				tt.title, m.MediaType, m.GetName(), m.BeginSeason, m.BeginEpisode, m.EndEpisode,
+				m.TotalEpisodes, m.IsSpecial, m.SpecialType)
+		}
+	}
+}
+
+func TestParseNameWhitespace(t *testing.T) {
+	tests := []struct {
+		title string
+		name  string
+	}{
+		{"One Piece - 1071 [1080p]", "One Piece"},
+		{"The.Long.Season.S01E02.2023.1080p.WEB-DL", "The Long Season"},
+		{"Breaking Bad (2008)", "Breaking Bad"},
+		{"Some  Movie   2019 1080p", "Some Movie"},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, false).GetMeta()
+		if m.EnName != tt.name {
+			t.Errorf("EnName of %q = %q, want %q", tt.title, m.EnName, tt.name)
+		}
+	}
+}
+
+func TestParseJpKrName(t *testing.T) {
+	tests := []struct {
+		title  string
+		cnName string
+		enName string
+		jpName string
+		krName string
+		year   int
+	}{
+		{"葬送のフリーレン.S01E05.1080p", "", "", "葬送のフリーレン", "", 0},
+		{"오징어 게임.S01E01.1080p.NF", "", "", "", "오징어 게임", 0},
+		{"进击的巨人.進撃の巨人.S04E01.1080p", "进击的巨人", "", "進撃の巨人", "", 0},
+		{"寄生虫.기생충.2019.1080p", "寄生虫", "", "", "기생충", 2019},
+		{"千与千寻.Spirited.Away.2001.1080p", "千与千寻", "Spirited Away", "", "", 2001},
+		// 只有日文、韩文名时年份不是英文名
+		{"君の名は。.2016.1080p.BluRay", "", "", "君の名は。", "", 2016},
+		{"オッペンハイマー.2023.2160p", "", "", "オッペンハイマー", "", 2023},
+		{"기생충.2019.1080p.BluRay", "", "", "", "기생충", 2019},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, false).GetMeta()
+		if m.CnName != tt.cnName || m.EnName != tt.enName || m.JpName != tt.jpName || m.KrName != tt.krName || m.Year != tt.year {
+			t.Errorf("%q: got cn %q en %q jp %q kr %q year %d", tt.title, m.CnName, m.EnName, m.JpName, m.KrName, m.Year)
+		}
+	}
+}
+
+func TestParseAirDate(t *testing.T) {
+	tests := []struct {
+		title    string
+		subtitle string
+		name     string
+		season   int
+		episode  int
+		airDate  string
+	}{
+		{"Show.2023.01.14.1080p.HDTV", "", "Show", 0, 0, "2023-01-14"},
+		{"The.Daily.Show.2024.03.07.Guest.720p.WEB", "", "The Daily Show", 0, 0, "2024-03-07"},
+		{"Show.20231014.1080p.WEB-DL", "", "Show", 0, 0, "2023-10-14"},
+		{"2023.01.14.Show.1080p", "", "Show", 0, 0, "2023-01-14"},
+		{"快乐大本营.20230114.1080p", "", "快乐大本营", 0, 0, "2023-01-14"},
+		{"快乐大本营.第20230114期.1080p", "", "快乐大本营", 0, 0, "2023-01-14"},
+		{"快乐大本营.2023年1月14日.1080p", "", "快乐大本营", 0, 0, "2023-01-14"},
+		{"奔跑吧.2023-01-14.第12期.1080p", "", "奔跑吧", 0, 12, "2023-01-14"},
+		{"向往的生活 第5季 第20210423期", "", "向往的生活", 5, 0, "2021-04-23"},
+		{"向往的生活.S05.1080p", "第20210423期", "向往的生活", 5, 0, "2021-04-23"},
+		{"向往的生活.第5季.第12期.1080p", "", "向往的生活", 5, 12, ""},
+		// 不是合法日期时不识别为播出日期
+		{"Daily.Show.2023.13.45.1080p", "", "Daily Show", 0, 0, ""},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, tt.subtitle, MediaUnknown, false).GetMeta()
+		if m.GetName() != tt.name || m.BeginSeason != tt.season || m.BeginEpisode != tt.episode || m.AirDate != tt.airDate {
+			t.Errorf("%q %q: got name %q season %d episode %d air date %q", tt.title, tt.subtitle,
+				m.GetName(), m.BeginSeason, m.BeginEpisode, m.AirDate)
+		}
+		if tt.airDate != "" && m.MediaType != MediaTypeTv {
+			t.Errorf("%q: type %d, want tv", tt.title, m.MediaType)
+		}
+	}
+}
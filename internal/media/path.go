@@ -0,0 +1,111 @@
+package media
+
+import (
+	"mediahub/internal/utils"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	PathSeasonRe  = regexp.MustCompile(`(?i)^(?:Season|S)[\s._\-]*(\d{1,2})$|^第\s*([0-9一二三四五六七八九十]+)\s*季$`)
+	PathSpecialRe = regexp.MustCompile(`(?i)^(?:Specials?|SPs?|特别篇|番外篇?)$`)
+)
+
+// NewMetaPath 识别带目录的文件路径，合并上级目录中的名称、年份和季
+//
+//	Show Name (2019)/Season 02/05.mkv
+//	某剧/第二季/E05.mp4
+func NewMetaPath(filePath string, mediaType int) MetaInfo {
+	parts := splitPath(filePath)
+	if len(parts) == 0 {
+		return nil
+	}
+	meta := NewMeta(parts[len(parts)-1], "", mediaType, IsMediaFile(filePath))
+	if meta == nil {
+		return nil
+	}
+	m := meta.GetMeta()
+	// 由近及远，先识别季目录，遇到第一个非季目录作为剧集目录
+	for i := len(parts) - 2; i >= 0; i-- {
+		dir := parts[i]
+		if season, ok := parsePathSeason(dir); ok {
+			if m.BeginSeason == 0 && !m.IsSpecial {
+				m.BeginSeason = season
+				m.TotalSeasons = 1
+				m.IsSpecial = season == 0
+			}
+			m.MediaType = MediaTypeTv
+			continue
+		}
+		mergeFolder(m, NewMeta(dir, "", mediaType, false))
+		break
+	}
+	m.OrgString = filePath
+	return meta
+}
+
+func splitPath(filePath string) []string {
+	filePath = strings.ReplaceAll(filePath, "\\", "/")
+	parts := make([]string, 0, 4)
+	for _, p := range strings.Split(filePath, "/") {
+		if p = strings.TrimSpace(p); p != "" && p != "." {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// parsePathSeason 识别季目录，特别篇目录为第 0 季
+func parsePathSeason(dir string) (int, bool) {
+	if PathSpecialRe.MatchString(dir) {
+		return 0, true
+	}
+	match := PathSeasonRe.FindStringSubmatch(dir)
+	if match == nil {
+		return 0, false
+	}
+	if match[1] != "" {
+		season, _ := strconv.Atoi(match[1])
+		return season, true
+	}
+	season := utils.CnToNumber(match[2], -1)
+	if season < 0 {
+		return 0, false
+	}
+	return int(season), true
+}
+
+// mergeFolder 用剧集目录的识别结果补充文件的名称、年份和季
+func mergeFolder(m *Meta, folder MetaInfo) {
+	if folder == nil {
+		return
+	}
+	f := folder.GetMeta()
+	if f.GetName() == "" {
+		return
+	}
+	// 文件名中没有名称，或者只有季集信息时，使用目录名称
+	if m.GetName() == "" {
+		m.CnName = f.CnName
+		m.EnName = f.EnName
+		m.JpName = f.JpName
+		m.KrName = f.KrName
+	}
+	if m.Year == 0 {
+		m.Year = f.Year
+	}
+	if m.BeginSeason == 0 && f.BeginSeason != 0 {
+		m.BeginSeason = f.BeginSeason
+		m.EndSeason = f.EndSeason
+		m.TotalSeasons = f.TotalSeasons
+	}
+	if m.ReleaseGroup == "" {
+		m.ReleaseGroup = f.ReleaseGroup
+	}
+	// 剧集目录中的 ID 标签，如 Show (2019) {tmdb-1396}
+	m.setIds(ExternalIds{TmdbId: f.TmdbId, ImdbId: f.ImdbId, TvdbId: f.TvdbId, DoubanId: f.DoubanId})
+	if m.BeginSeason != 0 || m.BeginEpisode != 0 || f.MediaType == MediaTypeTv {
+		m.MediaType = MediaTypeTv
+	}
+}
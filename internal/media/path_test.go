@@ -0,0 +1,72 @@
+package media
+
+import "testing"
+
+func TestNewMetaPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		name      string
+		mediaType int
+		year      int
+		season    int
+		episode   int
+		special   bool
+	}{
+		{"/media/Breaking Bad (2008)/Season 2/S02E03.mkv", "Breaking Bad", MediaTypeTv, 2008, 2, 3, false},
+		{"/media/tv/绝命毒师/第2季/第03集.mp4", "绝命毒师", MediaTypeTv, 0, 2, 3, false},
+		{"/media/tv/绝命毒师/第二季/E03.mp4", "绝命毒师", MediaTypeTv, 0, 2, 3, false},
+		{`D:\TV\The Office (US)\S03\The.Office.S03E05.720p.mkv`, "The Office", MediaTypeTv, 0, 3, 5, false},
+		{"/media/Movies/Inception (2010)/Inception.2010.1080p.BluRay.mkv", "Inception", MediaTypeMovie, 2010, 0, 0, false},
+		{"/downloads/Show.S01.1080p.WEB-DL/Show.S01E02.1080p.WEB-DL.mkv", "Show", MediaTypeTv, 0, 1, 2, false},
+		{"/media/Show/Season 01/05.mkv", "Show", MediaTypeTv, 0, 1, 5, false},
+		{"/media/Show/Specials/S00E01.mkv", "Show", MediaTypeTv, 0, 0, 1, true},
+		{"/media/[ANi] Frieren/[ANi] Frieren - 05 [1080P].mp4", "Frieren", MediaTypeTv, 0, 0, 5, false},
+		{"S01E01.mkv", "", MediaTypeTv, 0, 1, 1, false},
+	}
+	for _, tt := range tests {
+		meta := NewMetaPath(tt.path, MediaUnknown)
+		if meta == nil {
+			t.Errorf("NewMetaPath(%q) = nil", tt.path)
+			continue
+		}
+		m := meta.GetMeta()
+		if m.GetName() != tt.name || m.MediaType != tt.mediaType || m.Year != tt.year ||
+			m.BeginSeason != tt.season || m.BeginEpisode != tt.episode || m.IsSpecial != tt.special {
+			t.Errorf("%q: got name %q type %d year %d season %d episode %d special %v", tt.path,
+				m.GetName(), m.MediaType, m.Year, m.BeginSeason, m.BeginEpisode, m.IsSpecial)
+		}
+		if m.OrgString != tt.path || !m.IsFile {
+			t.Errorf("%q: OrgString %q IsFile %v", tt.path, m.OrgString, m.IsFile)
+		}
+	}
+	for _, p := range []string{"", "/", "./"} {
+		if meta := NewMetaPath(p, MediaUnknown); meta != nil {
+			t.Errorf("NewMetaPath(%q) = %+v, want nil", p, meta.GetMeta())
+		}
+	}
+}
+
+func TestParsePathSeason(t *testing.T) {
+	tests := []struct {
+		dir    string
+		season int
+		ok     bool
+	}{
+		{"Season 2", 2, true},
+		{"Season.02", 2, true},
+		{"S03", 3, true},
+		{"s1", 1, true},
+		{"第2季", 2, true},
+		{"第十二季", 12, true},
+		{"Specials", 0, true},
+		{"特别篇", 0, true},
+		{"Show S01", 0, false},
+		{"Breaking Bad (2008)", 0, false},
+	}
+	for _, tt := range tests {
+		season, ok := parsePathSeason(tt.dir)
+		if season != tt.season || ok != tt.ok {
+			t.Errorf("parsePathSeason(%q) = %d, %v, want %d, %v", tt.dir, season, ok, tt.season, tt.ok)
+		}
+	}
+}
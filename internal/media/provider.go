@@ -0,0 +1,211 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	ProviderTmdb   = "tmdb"
+	ProviderDouban = "douban"
+	ProviderTvdb   = "tvdb"
+)
+
+var (
+	DefaultProviders = []string{ProviderTmdb, ProviderDouban, ProviderTvdb}
+	ErrNoProvider    = errors.New("no metadata provider")
+)
+
+// Provider 元数据来源，id 统一使用字符串
+type Provider interface {
+	Name() string
+	// SearchMedia 按名称搜索电影和电视剧
+	SearchMedia(name string) ([]*SearchResult, error)
+	GetMovieInfo(id string) (*MediaDetail, error)
+	GetTvInfo(id string) (*MediaDetail, error)
+	GetExternalIds(id string, mediaType int) (*ExternalIds, error)
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Provider      string  `json:"provider"`
+	Id            string  `json:"id"`
+	MediaType     int     `json:"media_type"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	Year          int     `json:"year"`
+	Popularity    float32 `json:"popularity"`
+	Score         float64 `json:"score"` // 匹配得分
+}
+
+// MediaDetail 媒体详情
+type MediaDetail struct {
+	Provider      string      `json:"provider"`
+	Id            string      `json:"id"`
+	MediaType     int         `json:"media_type"`
+	Title         string      `json:"title"`
+	OriginalTitle string      `json:"original_title"`
+	AlsoKnownAs   []string    `json:"also_known_as"` // 其他语言和地区的标题
+	Year          int         `json:"year"`
+	ReleaseDate   string      `json:"release_date"`
+	Genres        []string    `json:"genres"`
+	GenreIds      []int       `json:"genre_ids"`         // TMDB 类型 ID
+	Language      string      `json:"original_language"` // 原始语言，ISO 639-1
+	Countries     []string    `json:"countries"`         // 出品国家，ISO 3166-1
+	Overview      string      `json:"overview"`
+	Runtime       int         `json:"runtime"`
+	Rating        float32     `json:"rating"`
+	Ids           ExternalIds `json:"ids"`
+}
+
+// ExternalIds 各来源的 ID
+type ExternalIds struct {
+	TmdbId   int    `json:"tmdb_id"`
+	ImdbId   string `json:"imdb_id"`
+	TvdbId   int    `json:"tvdb_id"`
+	DoubanId int    `json:"douban_id"`
+}
+
+// Merge 补充为空的 ID
+func (ids *ExternalIds) Merge(other ExternalIds) {
+	if ids.TmdbId == 0 {
+		ids.TmdbId = other.TmdbId
+	}
+	if ids.ImdbId == "" {
+		ids.ImdbId = other.ImdbId
+	}
+	if ids.TvdbId == 0 {
+		ids.TvdbId = other.TvdbId
+	}
+	if ids.DoubanId == 0 {
+		ids.DoubanId = other.DoubanId
+	}
+}
+
+// Set 设置来源自己的 ID
+func (ids *ExternalIds) Set(provider string, id string) {
+	switch provider {
+	case ProviderTmdb:
+		ids.TmdbId, _ = strconv.Atoi(id)
+	case ProviderTvdb:
+		ids.TvdbId, _ = strconv.Atoi(id)
+	case ProviderDouban:
+		ids.DoubanId, _ = strconv.Atoi(id)
+	}
+}
+
+// Get 返回来源自己的 ID，没有时为空
+func (ids *ExternalIds) Get(provider string) string {
+	id := 0
+	switch provider {
+	case ProviderTmdb:
+		id = ids.TmdbId
+	case ProviderTvdb:
+		id = ids.TvdbId
+	case ProviderDouban:
+		id = ids.DoubanId
+	}
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// getDetail 按类型获取详情
+func getDetail(p Provider, id string, mediaType int) (*MediaDetail, error) {
+	if mediaType == MediaTypeTv {
+		return p.GetTvInfo(id)
+	}
+	return p.GetMovieInfo(id)
+}
+
+// mergeDetails 按来源优先级合并详情，靠前的来源优先
+func mergeDetails(details []*MediaDetail) *MediaDetail {
+	merged := *details[0]
+	for _, d := range details[1:] {
+		if merged.Title == "" {
+			merged.Title = d.Title
+		}
+		if merged.OriginalTitle == "" {
+			merged.OriginalTitle = d.OriginalTitle
+		}
+		if merged.Year == 0 {
+			merged.Year = d.Year
+		}
+		if merged.ReleaseDate == "" {
+			merged.ReleaseDate = d.ReleaseDate
+		}
+		if len(merged.Genres) == 0 {
+			merged.Genres = d.Genres
+		}
+		if len(merged.GenreIds) == 0 {
+			merged.GenreIds = d.GenreIds
+		}
+		if merged.Language == "" {
+			merged.Language = d.Language
+		}
+		if len(merged.Countries) == 0 {
+			merged.Countries = d.Countries
+		}
+		if merged.Overview == "" {
+			merged.Overview = d.Overview
+		}
+		if merged.Runtime == 0 {
+			merged.Runtime = d.Runtime
+		}
+		if merged.Rating == 0 {
+			merged.Rating = d.Rating
+		}
+		merged.Ids.Merge(d.Ids)
+	}
+	return &merged
+}
+
+type statusError struct {
+	Code int
+	Msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.Msg
+}
+
+// newHttpClient 创建带代理的 http client
+func newHttpClient(proxyUrl string) http.Client {
+	var proxy func(*http.Request) (*url.URL, error)
+	if proxyUrl != "" {
+		proxyUrl_, err := url.Parse(proxyUrl)
+		if err != nil {
+			log.Errorf("parse proxy url failed, %s", err.Error())
+		} else {
+			proxy = http.ProxyURL(proxyUrl_)
+		}
+	}
+	return http.Client{
+		Timeout: time.Second * 5,
+		Transport: &http.Transport{
+			Proxy:           proxy,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 15 * time.Second,
+		},
+	}
+}
+
+// getJson 发送请求并解析 json 响应
+func getJson(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{Code: resp.StatusCode, Msg: fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, resp.Status)}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,250 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// handlerTransport 在进程内用 handler 响应请求，不区分域名
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestExternalIds(t *testing.T) {
+	ids := ExternalIds{TmdbId: 27205}
+	ids.Merge(ExternalIds{TmdbId: 1, ImdbId: "tt1375666", DoubanId: 3541415})
+	if want := (ExternalIds{TmdbId: 27205, ImdbId: "tt1375666", DoubanId: 3541415}); ids != want {
+		t.Errorf("Merge = %+v, want %+v", ids, want)
+	}
+
+	ids.Set(ProviderTvdb, "81189")
+	ids.Set(ProviderDouban, "x")
+	ids.Set("imdb", "tt0000001")
+	if want := (ExternalIds{TmdbId: 27205, ImdbId: "tt1375666", TvdbId: 81189}); ids != want {
+		t.Errorf("Set = %+v, want %+v", ids, want)
+	}
+
+	tests := map[string]string{
+		ProviderTmdb:   "27205",
+		ProviderTvdb:   "81189",
+		ProviderDouban: "",
+		"imdb":         "",
+	}
+	for provider, want := range tests {
+		if got := ids.Get(provider); got != want {
+			t.Errorf("Get(%q) = %q, want %q", provider, got, want)
+		}
+	}
+}
+
+func TestMergeDetails(t *testing.T) {
+	details := []*MediaDetail{
+		{Provider: ProviderTmdb, Id: "27205", Title: "盗梦空间", Year: 2010, Genres: []string{"动作"}, Ids: ExternalIds{TmdbId: 27205}},
+		{Provider: ProviderDouban, Id: "3541415", Title: "盗梦空间 Inception", Year: 2011, Overview: "道姆·柯布", Rating: 9.4,
+			Countries: []string{"US"}, Genres: []string{"剧情"}, Ids: ExternalIds{ImdbId: "tt1375666"}},
+		{Provider: ProviderTvdb, Runtime: 148, Rating: 8},
+	}
+	got := mergeDetails(details)
+	want := &MediaDetail{Provider: ProviderTmdb, Id: "27205", Title: "盗梦空间", Year: 2010, Genres: []string{"动作"},
+		Overview: "道姆·柯布", Rating: 9.4, Countries: []string{"US"}, Runtime: 148,
+		Ids: ExternalIds{TmdbId: 27205, ImdbId: "tt1375666"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeDetails = %+v, want %+v", got, want)
+	}
+	if details[0].Overview != "" {
+		t.Errorf("mergeDetails modified the first detail")
+	}
+}
+
+func TestRecognizeProviders(t *testing.T) {
+	tmdb := &fakeProvider{
+		name:    ProviderTmdb,
+		results: map[string][]*SearchResult{"Inception": {{Id: "27205", Title: "Inception", MediaType: MediaTypeMovie, Year: 2010}}},
+		details: map[string]*MediaDetail{"27205": {Id: "27205", Title: "盗梦空间", OriginalTitle: "Inception",
+			MediaType: MediaTypeMovie, Year: 2010, Ids: ExternalIds{ImdbId: "tt1375666"}}},
+	}
+	// 豆瓣用第一个来源的标准标题搜索
+	douban := &fakeProvider{
+		name:    ProviderDouban,
+		results: map[string][]*SearchResult{"盗梦空间": {{Id: "3541415", Title: "盗梦空间", MediaType: MediaTypeMovie, Year: 2010}}},
+		details: map[string]*MediaDetail{"3541415": {Id: "3541415", Title: "盗梦空间", MediaType: MediaTypeMovie, Year: 2010, Rating: 9.4}},
+	}
+	meta := NewMeta("Inception.2010.1080p.BluRay", "", MediaUnknown, false)
+	if err := NewMedia(tmdb, douban).Recognize(meta); err != nil {
+		t.Fatalf("Recognize failed, %s", err.Error())
+	}
+	info := meta.GetMeta()
+	if info.TmdbId != 27205 || info.DoubanId != 3541415 || info.ImdbId != "tt1375666" || info.Title != "盗梦空间" || info.Rating != 9.4 {
+		t.Errorf("Recognize = %d %d %q %q %.1f", info.TmdbId, info.DoubanId, info.ImdbId, info.Title, info.Rating)
+	}
+
+	// 标题中有豆瓣 ID 时直接获取详情
+	douban.results = nil
+	meta = NewMeta("Inception.2010.1080p.BluRay [douban-3541415]", "", MediaUnknown, false)
+	if err := NewMedia(douban).Recognize(meta); err != nil || meta.GetMeta().Rating != 9.4 {
+		t.Errorf("Recognize by id = %v", err)
+	}
+
+	// 全部来源失败时返回失败原因
+	tmdb.err = errors.New("connection refused")
+	meta = NewMeta("Inception.2010.1080p.BluRay", "", MediaUnknown, false)
+	if err := NewMedia(tmdb, douban).Recognize(meta); !errors.Is(err, tmdb.err) || !strings.HasPrefix(err.Error(), ProviderTmdb) {
+		t.Errorf("Recognize error %v, want %v", err, tmdb.err)
+	}
+	if err := NewMedia(douban).Recognize(meta); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("Recognize error %v, want %v", err, ErrMediaNotFound)
+	}
+	if err := NewMedia().Recognize(meta); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("Recognize error %v, want %v", err, ErrNoProvider)
+	}
+}
+
+func TestDouban(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/j/subject_suggest", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("q") != "盗梦空间" || r.Header.Get("User-Agent") != DoubanUserAgent {
+			writeJson(w, []interface{}{})
+			return
+		}
+		writeJson(w, []map[string]string{
+			{"id": "3541415", "title": "盗梦空间", "sub_title": "Inception", "type": "movie", "year": "2010"},
+			{"id": "1", "title": "盗梦空间 主演", "type": "celebrity"},
+			{"id": "2", "title": "盗梦空间 剧集", "type": "tv", "year": "2012"},
+		})
+	})
+	mux.HandleFunc("/rexxar/api/v2/movie/3541415", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		writeJson(w, map[string]interface{}{
+			"id": "3541415", "title": "盗梦空间", "original_title": "Inception", "year": "2010",
+			"genres": []string{"剧情", "科幻"}, "intro": "道姆·柯布", "rating": map[string]interface{}{"value": 9.4},
+			"pubdate": []string{"2010-09-01(中国大陆)", "2010-07-16(美国)"}, "durations": []string{"148分钟"},
+		})
+	})
+	d := NewDouban("")
+	d.client.Transport = handlerTransport{mux}
+
+	results, err := d.SearchMedia("盗梦空间")
+	if err != nil || len(results) != 2 {
+		t.Fatalf("SearchMedia = %d results, %v", len(results), err)
+	}
+	if r := results[0]; r.Id != "3541415" || r.MediaType != MediaTypeMovie || r.OriginalTitle != "Inception" || r.Year != 2010 ||
+		r.Popularity <= results[1].Popularity || results[1].MediaType != MediaTypeTv {
+		t.Errorf("SearchMedia = %+v %+v", r, results[1])
+	}
+	detail, err := d.GetMovieInfo("3541415")
+	want := &MediaDetail{Provider: ProviderDouban, Id: "3541415", MediaType: MediaTypeMovie, Title: "盗梦空间", OriginalTitle: "Inception",
+		Year: 2010, ReleaseDate: "2010-09-01", Genres: []string{"剧情", "科幻"}, Overview: "道姆·柯布", Runtime: 148, Rating: 9.4,
+		Ids: ExternalIds{DoubanId: 3541415}}
+	if err != nil || !reflect.DeepEqual(detail, want) {
+		t.Errorf("GetMovieInfo = %+v, %v", detail, err)
+	}
+
+	// 搜索结果和详情都缓存
+	_, _ = d.SearchMedia("盗梦空间")
+	_, _ = d.GetMovieInfo("3541415")
+	if requests != 2 {
+		t.Errorf("requested %d times, want 2", requests)
+	}
+	var statusErr *statusError
+	if _, err = d.GetTvInfo("3541415"); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("GetTvInfo error %v, want 404", err)
+	}
+	if _, err = d.GetMovieInfo("abc"); err == nil {
+		t.Errorf("GetMovieInfo with invalid id succeeded")
+	}
+}
+
+func TestTvdb(t *testing.T) {
+	logins := 0
+	token := ""
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v4/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != http.MethodPost || body["apikey"] != "key" || body["pin"] != "pin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		logins++
+		token = "token" + strings.Repeat("x", logins)
+		writeJson(w, map[string]interface{}{"data": map[string]string{"token": token}})
+	})
+	auth := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("/v4/search", auth(func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, map[string]interface{}{"data": []map[string]interface{}{
+			{"tvdb_id": "81189", "name": "Breaking Bad", "type": "series", "year": "2008", "translations": map[string]string{"zho": "绝命毒师"}},
+			{"tvdb_id": "1", "name": "Bryan Cranston", "type": "person"},
+			{"tvdb_id": "2", "name": "Breaking Bad Movie", "type": "movie", "year": "2019"},
+		}})
+	}))
+	mux.HandleFunc("/v4/series/81189/extended", auth(func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, map[string]interface{}{"data": map[string]interface{}{
+			"id": 81189, "name": "Breaking Bad", "year": "2008", "firstAired": "2008-01-20", "overview": "Walter White",
+			"averageRuntime": 47, "genres": []map[string]string{{"name": "Drama"}},
+			"remoteIds": []map[string]string{{"id": "tt0903747", "sourceName": "IMDB"}, {"id": "1396", "sourceName": "TheMovieDB.com"}},
+		}})
+	}))
+	mux.HandleFunc("/v4/series/81189/translations/zho", auth(func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, map[string]interface{}{"data": map[string]string{"name": "绝命毒师"}})
+	}))
+	tv := NewTvdb("key", "pin", "zh", "")
+	tv.client.Transport = handlerTransport{mux}
+
+	results, err := tv.SearchMedia("Breaking Bad")
+	if err != nil || len(results) != 2 {
+		t.Fatalf("SearchMedia = %d results, %v", len(results), err)
+	}
+	if r := results[0]; r.Id != "81189" || r.Title != "绝命毒师" || r.OriginalTitle != "Breaking Bad" || r.MediaType != MediaTypeTv ||
+		r.Year != 2008 || results[1].MediaType != MediaTypeMovie {
+		t.Errorf("SearchMedia = %+v %+v", r, results[1])
+	}
+
+	// token 失效后重新登录一次
+	token = "expired"
+	detail, err := tv.GetTvInfo("81189")
+	want := &MediaDetail{Provider: ProviderTvdb, Id: "81189", MediaType: MediaTypeTv, Title: "绝命毒师", OriginalTitle: "Breaking Bad",
+		Year: 2008, ReleaseDate: "2008-01-20", Genres: []string{"Drama"}, Overview: "Walter White", Runtime: 47,
+		Ids: ExternalIds{TmdbId: 1396, ImdbId: "tt0903747", TvdbId: 81189}}
+	if err != nil || !reflect.DeepEqual(detail, want) || logins != 2 {
+		t.Errorf("GetTvInfo = %+v, %v, %d logins", detail, err, logins)
+	}
+	if ids, err := tv.GetExternalIds("81189", MediaTypeTv); err != nil || *ids != want.Ids {
+		t.Errorf("GetExternalIds = %+v, %v", ids, err)
+	}
+	if _, err = tv.GetMovieInfo("81189"); err == nil {
+		t.Errorf("GetMovieInfo of series succeeded")
+	}
+
+	bad := NewTvdb("wrong", "", "en", "")
+	bad.client.Transport = handlerTransport{mux}
+	var statusErr *statusError
+	if _, err = bad.SearchMedia("Breaking Bad"); !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnauthorized {
+		t.Errorf("SearchMedia with wrong key error %v, want 401", err)
+	}
+}
@@ -0,0 +1,365 @@
+package media
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Resolution 分辨率
+type Resolution int
+
+const (
+	ResolutionUnknown Resolution = iota
+	Resolution480p
+	Resolution576p
+	Resolution720p
+	Resolution1080p
+	Resolution2160p
+	Resolution4320p
+)
+
+// Source 片源
+type Source int
+
+const (
+	SourceUnknown Source = iota
+	SourceCam
+	SourceTelesync
+	SourceDVD
+	SourceHDTV
+	SourceWEBRip
+	SourceWEBDL
+	SourceBluRay
+	SourceRemux
+)
+
+// HDR 动态范围
+type HDR int
+
+const (
+	HDRNone HDR = iota
+	HDRHLG
+	HDR10
+	HDR10Plus
+	HDRDolbyVision
+)
+
+// VideoCodec 视频编码
+type VideoCodec int
+
+const (
+	VideoCodecUnknown VideoCodec = iota
+	VideoCodecMPEG
+	VideoCodecH264
+	VideoCodecH265
+	VideoCodecAV1
+)
+
+// AudioCodec 音频编码
+type AudioCodec int
+
+const (
+	AudioCodecUnknown AudioCodec = iota
+	AudioCodecMP3
+	AudioCodecAAC
+	AudioCodecAC3
+	AudioCodecDDP
+	AudioCodecDTS
+	AudioCodecFLAC
+	AudioCodecLPCM
+	AudioCodecDTSHDMA
+	AudioCodecTrueHD
+	AudioCodecAtmos
+)
+
+var resolutionNames = []string{"unknown", "480p", "576p", "720p", "1080p", "2160p", "4320p"}
+var sourceNames = []string{"unknown", "cam", "telesync", "dvd", "hdtv", "webrip", "webdl", "bluray", "remux"}
+var hdrNames = []string{"none", "hlg", "hdr10", "hdr10plus", "dv"}
+var videoCodecNames = []string{"unknown", "mpeg", "h264", "h265", "av1"}
+var audioCodecNames = []string{"unknown", "mp3", "aac", "ac3", "ddp", "dts", "flac", "lpcm", "dtshdma", "truehd", "atmos"}
+
+func enumName(names []string, i int) string {
+	if i < 0 || i >= len(names) {
+		return names[0]
+	}
+	return names[i]
+}
+
+func (r Resolution) String() string { return enumName(resolutionNames, int(r)) }
+func (s Source) String() string     { return enumName(sourceNames, int(s)) }
+func (h HDR) String() string        { return enumName(hdrNames, int(h)) }
+func (c VideoCodec) String() string { return enumName(videoCodecNames, int(c)) }
+func (c AudioCodec) String() string { return enumName(audioCodecNames, int(c)) }
+
+func (r Resolution) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+func (s Source) MarshalText() ([]byte, error)     { return []byte(s.String()), nil }
+func (h HDR) MarshalText() ([]byte, error)        { return []byte(h.String()), nil }
+func (c VideoCodec) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+func (c AudioCodec) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+var (
+	QualityPixRe       = regexp.MustCompile(`(?i)(\d{3,4})[PI]|\d{3,4}X(\d{3,4})|([248])K`)
+	QualityCamRe       = regexp.MustCompile(`(?i)^(?:HD)?CAM(?:RIP)?$`)
+	QualityTelesyncRe  = regexp.MustCompile(`(?i)^(?:HD)?(?:TS|TC|TELESYNC|TELECINE)$`)
+	QualityShortTsRe   = regexp.MustCompile(`(?i)^T[SC]$`)
+	QualityRemuxRe     = regexp.MustCompile(`(?i)REMUX`)
+	QualityBluRayRe    = regexp.MustCompile(`(?i)BLU-?RAY|^BD$|BDRIP|UHD`)
+	QualityWebDLRe     = regexp.MustCompile(`(?i)WEB-?DL|^WEB$`)
+	QualityWebRipRe    = regexp.MustCompile(`(?i)WEB-?RIP`)
+	QualityHDTVRe      = regexp.MustCompile(`(?i)HDTV|HDRIP`)
+	QualityDVDRe       = regexp.MustCompile(`(?i)DVD`)
+	QualityDVRe        = regexp.MustCompile(`(?i)\b(?:DV|DOVI|DOLBY)\b`)
+	QualityHDR10PlusRe = regexp.MustCompile(`(?i)HDR10(?:\+|P|PLUS)`)
+	QualityHDRRe       = regexp.MustCompile(`(?i)HDR`)
+	QualityHLGRe       = regexp.MustCompile(`(?i)HLG`)
+	QualityAV1Re       = regexp.MustCompile(`(?i)AV1`)
+	QualityH265Re      = regexp.MustCompile(`(?i)[HX]\.?265|HEVC`)
+	QualityH264Re      = regexp.MustCompile(`(?i)[HX]\.?264|AVC`)
+	QualityMPEGRe      = regexp.MustCompile(`(?i)MPEG|XVID|DIVX|VC-?1`)
+	// QualityTokenRe 拆分原始标题，保留 token 两侧的括号，[TC] 之类括号内的是繁体字幕标签
+	QualityTokenRe = regexp.MustCompile(`[\[【]?[^.\s\-_\[\]【】()]+[\]】]?`)
+)
+
+// Quality 标准化的资源质量
+type Quality struct {
+	Resolution Resolution `json:"resolution"`
+	Source     Source     `json:"source"`
+	HDR        HDR        `json:"hdr"`
+	VideoCodec VideoCodec `json:"video_codec"`
+	AudioCodec AudioCodec `json:"audio_codec"`
+}
+
+// NewQuality 根据识别结果生成资源质量
+func NewQuality(m *Meta) Quality {
+	return Quality{
+		Resolution: parseResolution(m.ResourcePix),
+		Source:     parseSource(m.ResourceType, m.ResourceEffect, trimMediaExt(m.OrgString, m.IsFile)),
+		HDR:        parseHDR(m.ResourceEffect, m.VideoEncode),
+		VideoCodec: parseVideoCodec(m.VideoEncode),
+		AudioCodec: parseAudioCodec(m.AudioEncode),
+	}
+}
+
+func (q Quality) String() string {
+	return strings.Join([]string{q.Resolution.String(), q.Source.String(), q.HDR.String(),
+		q.VideoCodec.String(), q.AudioCodec.String()}, " ")
+}
+
+func parseResolution(pix string) Resolution {
+	match := QualityPixRe.FindStringSubmatch(pix)
+	if match == nil {
+		return ResolutionUnknown
+	}
+	switch {
+	case match[3] == "8":
+		return Resolution4320p
+	case match[3] == "4":
+		return Resolution2160p
+	case match[3] == "2":
+		return Resolution1080p
+	}
+	height := match[1]
+	if height == "" {
+		height = match[2]
+	}
+	switch height {
+	case "4320":
+		return Resolution4320p
+	case "2160":
+		return Resolution2160p
+	case "1080":
+		return Resolution1080p
+	case "720":
+		return Resolution720p
+	case "576":
+		return Resolution576p
+	case "480":
+		return Resolution480p
+	}
+	return ResolutionUnknown
+}
+
+// parseSource 片源，识别结果中没有时从原始标题中找 CAM、TS 等低质量片源
+func parseSource(typ, effect, title string) Source {
+	switch {
+	case QualityRemuxRe.MatchString(effect):
+		return SourceRemux
+	case QualityWebDLRe.MatchString(typ):
+		return SourceWEBDL
+	case QualityWebRipRe.MatchString(typ):
+		return SourceWEBRip
+	case QualityBluRayRe.MatchString(typ):
+		return SourceBluRay
+	case QualityHDTVRe.MatchString(typ):
+		return SourceHDTV
+	case QualityDVDRe.MatchString(typ):
+		return SourceDVD
+	}
+	return parseLowSource(title)
+}
+
+// parseLowSource 逐个 token 匹配 CAM、TS 等低质量片源，括号内的 TS、TC 不算
+func parseLowSource(title string) Source {
+	source := SourceUnknown
+	for _, token := range QualityTokenRe.FindAllString(title, -1) {
+		bracket := strings.HasPrefix(token, "[") || strings.HasPrefix(token, "【")
+		token = strings.Trim(token, "[]【】")
+		switch {
+		case QualityCamRe.MatchString(token):
+			return SourceCam
+		case QualityShortTsRe.MatchString(token) && bracket:
+		case QualityTelesyncRe.MatchString(token):
+			source = SourceTelesync
+		}
+	}
+	return source
+}
+
+// trimMediaExt 去掉文件扩展名，不是文件时只去掉小写的媒体扩展名，避免把 .TS 片源当作扩展名
+func trimMediaExt(title string, isFile bool) string {
+	ext := path.Ext(title)
+	if ext == "" || !IsMediaFile(title) && !IsSubtitleFile(title) {
+		return title
+	}
+	if isFile || ext == strings.ToLower(ext) {
+		return strings.TrimSuffix(title, ext)
+	}
+	return title
+}
+
+func parseHDR(effect, video string) HDR {
+	text := effect + " " + video
+	switch {
+	case QualityDVRe.MatchString(text):
+		return HDRDolbyVision
+	case QualityHDR10PlusRe.MatchString(text):
+		return HDR10Plus
+	case QualityHDRRe.MatchString(text):
+		return HDR10
+	case QualityHLGRe.MatchString(text):
+		return HDRHLG
+	}
+	return HDRNone
+}
+
+func parseVideoCodec(video string) VideoCodec {
+	switch {
+	case QualityAV1Re.MatchString(video):
+		return VideoCodecAV1
+	case QualityH265Re.MatchString(video):
+		return VideoCodecH265
+	case QualityH264Re.MatchString(video):
+		return VideoCodecH264
+	case QualityMPEGRe.MatchString(video):
+		return VideoCodecMPEG
+	}
+	return VideoCodecUnknown
+}
+
+func parseAudioCodec(audio string) AudioCodec {
+	audio = strings.ToUpper(strings.NewReplacer("-", "", " ", "", ".", "").Replace(audio))
+	switch {
+	case strings.Contains(audio, "ATMOS"):
+		return AudioCodecAtmos
+	case strings.Contains(audio, "TRUEHD"):
+		return AudioCodecTrueHD
+	case strings.Contains(audio, "DTSHD"), strings.Contains(audio, "DTSX"):
+		return AudioCodecDTSHDMA
+	case strings.Contains(audio, "LPCM"):
+		return AudioCodecLPCM
+	case strings.Contains(audio, "FLAC"):
+		return AudioCodecFLAC
+	case strings.Contains(audio, "DTS"):
+		return AudioCodecDTS
+	case strings.Contains(audio, "DDP"), strings.Contains(audio, "EAC3"):
+		return AudioCodecDDP
+	case strings.Contains(audio, "AC3"), strings.HasPrefix(audio, "DD"):
+		return AudioCodecAC3
+	case strings.Contains(audio, "AAC"):
+		return AudioCodecAAC
+	case strings.Contains(audio, "MP3"):
+		return AudioCodecMP3
+	}
+	return AudioCodecUnknown
+}
+
+// QualityScore 质量评分，各项按名称配置分值，未配置的项为 0
+type QualityScore struct {
+	Resolution map[string]int `json:"resolution"`
+	Source     map[string]int `json:"source"`
+	HDR        map[string]int `json:"hdr"`
+	VideoCodec map[string]int `json:"video_codec"`
+	AudioCodec map[string]int `json:"audio_codec"`
+}
+
+// DefaultQualityScore 默认评分，分辨率优先，其次片源、HDR、编码
+func DefaultQualityScore() QualityScore {
+	return QualityScore{
+		Resolution: map[string]int{"480p": 100, "576p": 150, "720p": 300, "1080p": 600, "2160p": 1000, "4320p": 1100},
+		Source:     map[string]int{"cam": -500, "telesync": -400, "dvd": 50, "hdtv": 100, "webrip": 150, "webdl": 200, "bluray": 250, "remux": 300},
+		HDR:        map[string]int{"hlg": 40, "hdr10": 60, "hdr10plus": 70, "dv": 80},
+		VideoCodec: map[string]int{"mpeg": 0, "h264": 20, "h265": 30, "av1": 35},
+		AudioCodec: map[string]int{"mp3": 0, "aac": 5, "ac3": 10, "ddp": 15, "dts": 20, "flac": 25, "lpcm": 25, "dtshdma": 30, "truehd": 35, "atmos": 40},
+	}
+}
+
+// Score 计算质量分
+func (s QualityScore) Score(q Quality) int {
+	return s.Resolution[q.Resolution.String()] +
+		s.Source[q.Source.String()] +
+		s.HDR[q.HDR.String()] +
+		s.VideoCodec[q.VideoCodec.String()] +
+		s.AudioCodec[q.AudioCodec.String()]
+}
+
+// Merge 用 o 中配置的分值覆盖当前评分
+func (s QualityScore) Merge(o QualityScore) QualityScore {
+	merge := func(dst, src map[string]int) map[string]int {
+		m := make(map[string]int, len(dst)+len(src))
+		for k, v := range dst {
+			m[k] = v
+		}
+		for k, v := range src {
+			m[strings.ToLower(k)] = v
+		}
+		return m
+	}
+	return QualityScore{
+		Resolution: merge(s.Resolution, o.Resolution),
+		Source:     merge(s.Source, o.Source),
+		HDR:        merge(s.HDR, o.HDR),
+		VideoCodec: merge(s.VideoCodec, o.VideoCodec),
+		AudioCodec: merge(s.AudioCodec, o.AudioCodec),
+	}
+}
+
+var qualityScore = struct {
+	sync.RWMutex
+	score QualityScore
+}{score: DefaultQualityScore()}
+
+// SetQualityScore 设置评分，在默认评分基础上覆盖
+func SetQualityScore(s QualityScore) {
+	qualityScore.Lock()
+	defer qualityScore.Unlock()
+	qualityScore.score = DefaultQualityScore().Merge(s)
+}
+
+// ScoreQuality 使用当前评分计算质量分
+func ScoreQuality(q Quality) int {
+	qualityScore.RLock()
+	defer qualityScore.RUnlock()
+	return qualityScore.score.Score(q)
+}
+
+// CompareQuality 比较同一媒体的两个资源，a 更好返回正数，b 更好返回负数，相同返回 0
+func CompareQuality(a, b *Meta) int {
+	return ScoreQuality(a.GetQuality()) - ScoreQuality(b.GetQuality())
+}
+
+// GetQuality 资源质量
+func (m *Meta) GetQuality() Quality {
+	return NewQuality(m)
+}
@@ -0,0 +1,119 @@
+package media
+
+import "testing"
+
+func TestNewQuality(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Quality
+	}{
+		{"The.Last.of.Us.S01E03.2160p.HMAX.WEB-DL.DDP5.1.Atmos.DV.HDR.H.265-FLUX",
+			Quality{Resolution2160p, SourceWEBDL, HDRDolbyVision, VideoCodecH265, AudioCodecAtmos}},
+		{"Oppenheimer.2023.2160p.UHD.BluRay.REMUX.HDR.HEVC.DTS-HD.MA.5.1-FRDS",
+			Quality{Resolution2160p, SourceRemux, HDR10, VideoCodecH265, AudioCodecDTSHDMA}},
+		{"Movie.2019.1080p.BluRay.x264.DTS-GRP",
+			Quality{Resolution1080p, SourceBluRay, HDRNone, VideoCodecH264, AudioCodecDTS}},
+		{"Movie.2019.1080p.WEB-DL.H264.AAC-GRP",
+			Quality{Resolution1080p, SourceWEBDL, HDRNone, VideoCodecH264, AudioCodecAAC}},
+		{"Show.1x05.720p.HDTV",
+			Quality{Resolution720p, SourceHDTV, HDRNone, VideoCodecUnknown, AudioCodecUnknown}},
+		{"Movie.2023.HDCAM.x264",
+			Quality{ResolutionUnknown, SourceCam, HDRNone, VideoCodecH264, AudioCodecUnknown}},
+		{"Movie.2023.TS.x264-GRP",
+			Quality{ResolutionUnknown, SourceTelesync, HDRNone, VideoCodecH264, AudioCodecUnknown}},
+		{"Movie 2023 HDTS 1080p",
+			Quality{Resolution1080p, SourceTelesync, HDRNone, VideoCodecUnknown, AudioCodecUnknown}},
+		{"Movie.2023.1080p.TC.mkv",
+			Quality{Resolution1080p, SourceTelesync, HDRNone, VideoCodecUnknown, AudioCodecUnknown}},
+		// .ts 是扩展名，[TC] 是繁体字幕标签
+		{"Some.Show.S01E01.720p.ts",
+			Quality{Resolution720p, SourceUnknown, HDRNone, VideoCodecUnknown, AudioCodecUnknown}},
+		{"[ANi] Frieren - 05 [1080P][TC].mp4",
+			Quality{Resolution1080p, SourceUnknown, HDRNone, VideoCodecUnknown, AudioCodecUnknown}},
+	}
+	for _, tt := range tests {
+		meta := NewMeta(tt.title, "", MediaUnknown, IsMediaFile(tt.title))
+		if meta == nil {
+			t.Fatalf("NewMeta(%q) = nil", tt.title)
+		}
+		if got := meta.GetMeta().GetQuality(); got != tt.want {
+			t.Errorf("quality of %q = %s, want %s", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestParseLowSource(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Source
+	}{
+		{"Movie.2023.CAMRip.x264", SourceCam},
+		{"Movie.2023.TELESYNC", SourceTelesync},
+		{"Movie.2023.HDTC.x264", SourceTelesync},
+		{"Movie.2023.[TS].x264", SourceUnknown},
+		{"Movie【TC】2023", SourceUnknown},
+		{"Tsubasa.2023.1080p", SourceUnknown},
+		{"Camera.2023.1080p", SourceUnknown},
+	}
+	for _, tt := range tests {
+		if got := parseLowSource(tt.title); got != tt.want {
+			t.Errorf("parseLowSource(%q) = %s, want %s", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestTrimMediaExt(t *testing.T) {
+	tests := []struct {
+		title  string
+		isFile bool
+		want   string
+	}{
+		{"Show.S01E01.720p.ts", false, "Show.S01E01.720p"},
+		{"Movie.2023.720p.TS", false, "Movie.2023.720p.TS"},
+		{"Movie.2023.720p.TS", true, "Movie.2023.720p"},
+		{"Movie.2023.srt", false, "Movie.2023"},
+		{"Movie.2023.H.264", false, "Movie.2023.H.264"},
+	}
+	for _, tt := range tests {
+		if got := trimMediaExt(tt.title, tt.isFile); got != tt.want {
+			t.Errorf("trimMediaExt(%q, %v) = %q, want %q", tt.title, tt.isFile, got, tt.want)
+		}
+	}
+}
+
+func TestQualityScore(t *testing.T) {
+	score := DefaultQualityScore()
+	remux := Quality{Resolution2160p, SourceRemux, HDR10, VideoCodecH265, AudioCodecDTSHDMA}
+	webdl := Quality{Resolution2160p, SourceWEBDL, HDRDolbyVision, VideoCodecH265, AudioCodecAtmos}
+	hd := Quality{Resolution1080p, SourceBluRay, HDRNone, VideoCodecH264, AudioCodecDTS}
+	cam := Quality{Resolution1080p, SourceCam, HDRNone, VideoCodecH264, AudioCodecAAC}
+	if got := score.Score(remux); got != 1000+300+60+30+30 {
+		t.Errorf("Score(remux) = %d", got)
+	}
+	if !(score.Score(remux) > score.Score(webdl) && score.Score(webdl) > score.Score(hd) && score.Score(hd) > score.Score(cam)) {
+		t.Errorf("unexpected order remux %d, webdl %d, 1080p %d, cam %d",
+			score.Score(remux), score.Score(webdl), score.Score(hd), score.Score(cam))
+	}
+
+	// 覆盖的分值不区分大小写，未覆盖的保留默认值
+	merged := score.Merge(QualityScore{Source: map[string]int{"WEBDL": 400}})
+	if merged.Source["webdl"] != 400 || merged.Source["remux"] != 300 {
+		t.Errorf("Merge source = %v", merged.Source)
+	}
+	if merged.Score(webdl) <= merged.Score(remux) {
+		t.Errorf("webdl %d should beat remux %d after override", merged.Score(webdl), merged.Score(remux))
+	}
+}
+
+func TestCompareQuality(t *testing.T) {
+	defer SetQualityScore(QualityScore{})
+	a := NewMeta("Movie.2019.2160p.BluRay.REMUX.HEVC.TrueHD-GRP", "", MediaUnknown, false).GetMeta()
+	b := NewMeta("Movie.2019.1080p.WEB-DL.H264.AAC-GRP", "", MediaUnknown, false).GetMeta()
+	if CompareQuality(a, b) <= 0 || CompareQuality(b, a) >= 0 || CompareQuality(a, a) != 0 {
+		t.Errorf("CompareQuality(2160p remux, 1080p webdl) = %d", CompareQuality(a, b))
+	}
+	SetQualityScore(QualityScore{Resolution: map[string]int{"2160p": 0}})
+	if CompareQuality(a, b) >= 0 {
+		t.Errorf("CompareQuality with 2160p scored 0 = %d, want negative", CompareQuality(a, b))
+	}
+}
@@ -0,0 +1,201 @@
+package media
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ReleaseGroups 发布组字典，按站点或类别分组，值为正则片段
+var ReleaseGroups = map[string][]string{
+	"0ff":          {`FF(?:(?:A|WE)B|CD|E(?:DU|B)|TV)`},
+	"audiences":    {`Audies`, `AD(?:Audio|E(?:|book)|Music|Web)`},
+	"beitai":       {`BeiTai`},
+	"btschool":     {`Bts(?:CHOOL|HD|PAD|TV)`, `Zone`},
+	"carpt":        {`CarPT`},
+	"chdbits":      {`CHD(?:|Bits|PAD|(?:|HK)TV|WEB)`, `StBOX`, `OneHD`, `Lee`, `xiaopie`},
+	"eastgame":     {`(?:(?:iNT|(?:HALFC|Mini(?:S|H|FH)D))-|)TLF`},
+	"frds":         {`FRDS`, `Yumi`, `cXcY`},
+	"hdarea":       {`HDA(?:pad|rea|TV)`, `EPiC`},
+	"hdchina":      {`HDC(?:hina|TV|)`, `k9611`, `tudou`, `iHD`},
+	"hddolby":      {`D(?:ream|BTV)`, `(?:HD|QHstudI)o`},
+	"hdfans":       {`beAst(?:|TV)`},
+	"hdhome":       {`HDH(?:ome|Pad|TV|WEB|)`},
+	"hdpt":         {`HDPT(?:|Web)`},
+	"hdsky":        {`HDS(?:|ky|TV|Pad|WEB)`, `AQLJ`},
+	"hdzone":       {`HDZ(?:|one)`},
+	"hhanclub":     {`HHWEB`},
+	"htpt":         {`HTPT`},
+	"joyhd":        {`JoyHD`},
+	"lemonhd":      {`L(?:eague(?:(?:C|H)D|(?:M|T)V|NF|WEB)|HD)`, `i18n`, `CiNT`},
+	"mteam":        {`MTeam(?:|TV)`, `MPAD`},
+	"ourbits":      {`Our(?:Bits|TV)`, `FLTTH`, `Ao`, `PbK`, `MGs`, `iLove(?:HD|TV)`},
+	"piggo":        {`PiGo(?:NF|(?:H|WE)B)`},
+	"pterclub":     {`PTer(?:|DIY|Game|(?:M|T)V|WEB)`},
+	"pthome":       {`PTH(?:|Audio|eBook|music|ome|tv|WEB)`},
+	"ptsbao":       {`PTsbao`, `OPS`, `F(?:Fans(?:AIeNcE|BD|D(?:VD|IY)|TV|WEB)|HDMv)`, `SGXT`},
+	"putao":        {`PuTao`},
+	"sharkpt":      {`Shark(?:|WEB|DIY|TV|MV)`},
+	"springsunday": {`CMCT(?:|V)`},
+	"tjupt":        {`TJUPT`},
+	"totheglory":   {`TTG`, `WiKi`, `NGB`, `DoA`, `(?:ARi|ExRE)N`},
+	"other": {`B(?:MDru|eyondHD|TN)`, `C(?:fandora|trlhd|MRG)`, `DON`, `EVO`, `FLUX`, `HONE(?:|yG)`,
+		`N(?:oGroup|T(?:b|G))`, `PandaMoon`, `SMURF`, `T(?:EPES|aengoo|rollHD)`, `RARBG`, `YTS(?:\.MX|)`},
+	"anime": {`ANi`, `喵萌奶茶屋`, `Nekomoe kissaten`, `LoliHouse`, `Lilith-Raws`, `桜都字幕组`, `动漫国字幕组`,
+		`SweetSub`, `NC-Raws`, `幻樱字幕组`, `北宇治字幕组`, `极影字幕社`, `千夏字幕组`, `澄空学园`, `华盟字幕社`,
+		`诸神字幕组`, `漫猫字幕组`, `风车字幕组`, `悠哈璃羽字幕社`, `天使动漫论坛`, `Sakurato`, `Airota`,
+		`VCB-Studio`, `Moozzi2`, `SumiSora`, `KissSub`, `Skymoon-Raws`, `jsum`, `GM-Team`, `Erai-raws`,
+		`SubsPlease`, `HorribleSubs`, `Ohys-Raws`, `Leopard-Raws`, `Judas`, `DBD-Raws`, `MingY`, `7³ACG`,
+		`c\.c动漫`, `云光字幕组`, `星空字幕组`, `霜庭云花Sub`, `织梦字幕组`, `离谱Sub`, `黒ネズミたち`},
+}
+
+var (
+	ReleaseGroupBeginRe  = regexp.MustCompile(`^\s*[\[【]([^]】]+)[]】]`)
+	ReleaseGroupSuffixRe = regexp.MustCompile(`-([A-Za-z0-9][\w&]*?)(@[A-Za-z0-9]+)?$`)
+	ReleaseGroupSiteRe   = regexp.MustCompile(`@([A-Za-z0-9]+)$`)
+	ReleaseGroupTechRe   = regexp.MustCompile(`(?i)[.\s\-_](?:\d{3,4}[pi]|[248]K|BluRay|WEB|HDTV|REMUX|[HX]26[45]|HEVC)(?:[.\s\-_]|$)`)
+	ReleaseGroupNoiseRe  = regexp.MustCompile(`(?i)^(?:DL|HD|MA|X|ES|RIP|\d+(?:\.\d)?|[HX]26[45]|` +
+		`DTS|DTSHD|AAC|AC3|FLAC|DDP?\d?|HDR\d*|10bit|WEB|BluRay|REMUX|DV|Atmos|TrueHD)$`)
+)
+
+var releaseGroups = NewReleaseGroupMatcher(ReleaseGroups)
+
+// ReleaseGroupMatcher 发布组匹配器，可在运行时追加发布组
+type ReleaseGroupMatcher struct {
+	sync.RWMutex
+	groups map[string][]string
+	re     *regexp.Regexp
+	animRe *regexp.Regexp
+}
+
+func NewReleaseGroupMatcher(groups map[string][]string) *ReleaseGroupMatcher {
+	m := &ReleaseGroupMatcher{
+		groups: make(map[string][]string, len(groups)),
+	}
+	for site, g := range groups {
+		m.groups[site] = append([]string(nil), g...)
+	}
+	m.compile()
+	return m
+}
+
+func (m *ReleaseGroupMatcher) compile() {
+	all := make([]string, 0, 128)
+	for _, g := range m.groups {
+		all = append(all, g...)
+	}
+	m.re = regexp.MustCompile(fmt.Sprintf(`(?i)(?:^|[\[\s.@\-_【&])(%s)(?:$|[]\s.@\-_】&])`, strings.Join(all, "|")))
+	m.animRe = regexp.MustCompile(fmt.Sprintf(`(?i)^(?:%s)$`, strings.Join(m.groups["anime"], "|")))
+}
+
+// Add 向指定站点追加发布组，组名按正则处理
+func (m *ReleaseGroupMatcher) Add(site string, groups ...string) error {
+	for _, g := range groups {
+		if _, err := regexp.Compile(g); err != nil {
+			return err
+		}
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.groups[site] = append(m.groups[site], groups...)
+	m.compile()
+	return nil
+}
+
+// IsGroup 判断是否已知发布组
+func (m *ReleaseGroupMatcher) IsGroup(group string) bool {
+	m.RLock()
+	defer m.RUnlock()
+	loc := m.re.FindStringSubmatchIndex(group)
+	return loc != nil && group[loc[2]:loc[3]] == group
+}
+
+// IsAnimGroup 判断是否动漫字幕组
+func (m *ReleaseGroupMatcher) IsAnimGroup(group string) bool {
+	m.RLock()
+	defer m.RUnlock()
+	return m.animRe.MatchString(group)
+}
+
+// Match 识别标题中的发布组，返回发布组和去掉发布组后的标题
+func (m *ReleaseGroupMatcher) Match(title string) (string, string) {
+	// 开头的 [发布组]
+	if match := ReleaseGroupBeginRe.FindStringSubmatchIndex(title); match != nil {
+		group := strings.TrimSpace(title[match[2]:match[3]])
+		if m.isGroups(group) {
+			return group, title[match[1]:]
+		}
+	}
+	// 结尾的 -GROUP 或 -GROUP@SITE，未知发布组需出现在技术参数之后
+	ext := ""
+	if IsMediaFile(title) {
+		ext = path.Ext(title)
+	}
+	name := strings.TrimSuffix(title, ext)
+	tech := ReleaseGroupTechRe.FindStringIndex(name)
+	if match := ReleaseGroupSuffixRe.FindStringSubmatchIndex(name); match != nil {
+		group := name[match[2]:match[3]]
+		if !ReleaseGroupNoiseRe.MatchString(group) && (tech != nil && tech[0] < match[0] || m.IsGroup(group)) {
+			if match[4] >= 0 {
+				group += name[match[4]:match[5]]
+			}
+			return group, name[:match[0]] + ext
+		}
+	}
+	// 结尾的 @SITE
+	if match := ReleaseGroupSiteRe.FindStringSubmatchIndex(name); match != nil {
+		if group := name[match[2]:match[3]]; m.IsGroup(group) {
+			return "@" + group, name[:match[0]] + ext
+		}
+	}
+	// 技术参数之后的已知发布组
+	if tech != nil {
+		m.RLock()
+		match := m.re.FindStringSubmatchIndex(name[tech[0]:])
+		m.RUnlock()
+		if match != nil {
+			begin, end := tech[0]+match[2], tech[0]+match[3]
+			return name[begin:end], name[:begin] + name[end:] + ext
+		}
+	}
+	return "", title
+}
+
+func (m *ReleaseGroupMatcher) isGroups(group string) bool {
+	for _, g := range strings.Split(group, "&") {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			continue
+		}
+		if m.IsGroup(g) || strings.HasSuffix(g, "字幕组") || strings.HasSuffix(g, "字幕社") {
+			return true
+		}
+	}
+	return false
+}
+
+// AddReleaseGroups 追加自定义发布组
+func AddReleaseGroups(site string, groups ...string) error {
+	return releaseGroups.Add(site, groups...)
+}
+
+// MatchReleaseGroup 识别标题中的发布组
+func MatchReleaseGroup(title string) (string, string) {
+	return releaseGroups.Match(title)
+}
+
+// IsAnimGroup 判断是否动漫字幕组，多个组用 & 连接时任一命中即可
+func IsAnimGroup(group string) bool {
+	for _, g := range strings.Split(group, "&") {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			continue
+		}
+		if releaseGroups.IsAnimGroup(g) || strings.HasSuffix(g, "字幕组") || strings.HasSuffix(g, "字幕社") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package media
+
+import "testing"
+
+func TestMatchReleaseGroup(t *testing.T) {
+	tests := []struct {
+		title string
+		group string
+		rest  string
+	}{
+		{"Inception.2010.1080p.BluRay.x264-CMCT", "CMCT", "Inception.2010.1080p.BluRay.x264"},
+		{"Inception.2010.1080p.BluRay.x264-CMCT.mkv", "CMCT", "Inception.2010.1080p.BluRay.x264.mkv"},
+		// 技术参数之后的未知发布组
+		{"Inception.2010.1080p.BluRay.x264-UnknownGrp", "UnknownGrp", "Inception.2010.1080p.BluRay.x264"},
+		{"Inception.2010.1080p.BluRay.DTS-HD.MA.5.1", "", "Inception.2010.1080p.BluRay.DTS-HD.MA.5.1"},
+		{"Show.S01E01.1080p.WEB-DL.H264-HHWEB@HHCLUB", "HHWEB@HHCLUB", "Show.S01E01.1080p.WEB-DL.H264"},
+		{"Show.S01E01.1080p.WEB-DL.H264@CHDBits", "@CHDBits", "Show.S01E01.1080p.WEB-DL.H264"},
+		{"Show.S01E01.2160p.WEB-DL.H265.DDP5.1.FRDS.mkv", "FRDS", "Show.S01E01.2160p.WEB-DL.H265.DDP5.1..mkv"},
+		{"Show.S01E01-DON", "DON", "Show.S01E01"},
+		{"[ANi] Frieren - 05 [1080P]", "ANi", " Frieren - 05 [1080P]"},
+		{"[喵萌奶茶屋&LoliHouse] Frieren - 05", "喵萌奶茶屋&LoliHouse", " Frieren - 05"},
+		{"[UnknownSub] Frieren - 05", "", "[UnknownSub] Frieren - 05"},
+		// 标题中的连字符不是发布组
+		{"The-Movie-2010", "", "The-Movie-2010"},
+		{"Spider-Man.2002.1080p", "", "Spider-Man.2002.1080p"},
+	}
+	m := NewReleaseGroupMatcher(ReleaseGroups)
+	for _, tt := range tests {
+		group, rest := m.Match(tt.title)
+		if group != tt.group || rest != tt.rest {
+			t.Errorf("Match(%q) = %q, %q, want %q, %q", tt.title, group, rest, tt.group, tt.rest)
+		}
+	}
+}
+
+func TestReleaseGroupMatcher(t *testing.T) {
+	m := NewReleaseGroupMatcher(ReleaseGroups)
+	tests := []struct {
+		group string
+		is    bool
+		anim  bool
+	}{
+		{"CMCT", true, false},
+		{"cmctv", true, false},
+		{"CMCTX", false, false},
+		{"LoliHouse", true, true},
+		{"MyGrp", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.IsGroup(tt.group); got != tt.is {
+			t.Errorf("IsGroup(%q) = %v, want %v", tt.group, got, tt.is)
+		}
+		if got := m.IsAnimGroup(tt.group); got != tt.anim {
+			t.Errorf("IsAnimGroup(%q) = %v, want %v", tt.group, got, tt.anim)
+		}
+	}
+	if err := m.Add("mysite", "MyGrp"); err != nil {
+		t.Fatalf("Add failed, %s", err.Error())
+	}
+	if !m.IsGroup("MyGrp") {
+		t.Errorf("IsGroup(MyGrp) = false after Add")
+	}
+	if group, _ := m.Match("Show.S01E01-MyGrp"); group != "MyGrp" {
+		t.Errorf("Match after Add = %q, want MyGrp", group)
+	}
+	if err := m.Add("mysite", "("); err == nil {
+		t.Errorf("Add invalid group succeeded")
+	}
+	if !IsAnimGroup("喵萌奶茶屋&XX") || !IsAnimGroup("某某字幕组") || IsAnimGroup("CMCT") {
+		t.Errorf("IsAnimGroup with & and 字幕组 suffix failed")
+	}
+}
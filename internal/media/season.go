@@ -0,0 +1,138 @@
+package media
+
+import (
+	tmdb "github.com/cyruzin/golang-tmdb"
+	"strconv"
+)
+
+// Season 季详情，第 0 季为特别篇
+type Season struct {
+	ShowId       int        `json:"show_id"`
+	SeasonNumber int        `json:"season_number"`
+	Name         string     `json:"name"`
+	Overview     string     `json:"overview"`
+	AirDate      string     `json:"air_date"`
+	EpisodeCount int        `json:"episode_count"`
+	Episodes     []*Episode `json:"episodes,omitempty"` // 只有季详情包含
+}
+
+// Episode 集详情
+type Episode struct {
+	Id            int    `json:"id"`
+	ShowId        int    `json:"show_id"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	Name          string `json:"name"`
+	Overview      string `json:"overview"`
+	AirDate       string `json:"air_date"` // yyyy-mm-dd，未定档时为空
+	Runtime       int    `json:"runtime"`  // 只有集详情包含
+}
+
+func (s *Season) IsSpecial() bool {
+	return s.SeasonNumber == 0
+}
+
+// GetEpisode 按集数查找
+func (s *Season) GetEpisode(number int) *Episode {
+	for _, e := range s.Episodes {
+		if e.EpisodeNumber == number {
+			return e
+		}
+	}
+	return nil
+}
+
+// FindByAirDate 按播出日期查找，日播节目一天可能有多集
+func (s *Season) FindByAirDate(date string) []*Episode {
+	episodes := make([]*Episode, 0, 1)
+	for _, e := range s.Episodes {
+		if e.AirDate != "" && e.AirDate == date {
+			episodes = append(episodes, e)
+		}
+	}
+	return episodes
+}
+
+// AiredEpisodes 在 date 当天及之前播出的集，用于缺集检查
+func (s *Season) AiredEpisodes(date string) []*Episode {
+	episodes := make([]*Episode, 0, len(s.Episodes))
+	for _, e := range s.Episodes {
+		if e.AirDate != "" && e.AirDate <= date {
+			episodes = append(episodes, e)
+		}
+	}
+	return episodes
+}
+
+// GetSeasons 剧集的全部季，不包含集列表
+func (t *Tmdb) GetSeasons(id int) ([]*Season, error) {
+	detail, err := t.GetTvDetail(id)
+	if err != nil {
+		return nil, err
+	}
+	seasons := make([]*Season, 0, len(detail.Seasons))
+	for _, s := range detail.Seasons {
+		seasons = append(seasons, &Season{
+			ShowId:       id,
+			SeasonNumber: s.SeasonNumber,
+			Name:         s.Name,
+			Overview:     s.Overview,
+			AirDate:      s.AirDate,
+			EpisodeCount: s.EpisodeCount,
+		})
+	}
+	return seasons, nil
+}
+
+// GetSeasonDetail 季详情，包含集列表。季和集随播出更新，与搜索结果使用相同的有效期
+func (t *Tmdb) GetSeasonDetail(id int, season int) (*Season, error) {
+	key := t.cacheKey(TmdbCacheSeason, strconv.Itoa(id)+":"+strconv.Itoa(season))
+	detail, err := tmdbLoad(t, key, t.searchTTL, func() (*tmdb.TVSeasonDetails, error) {
+		return t.client.GetTVSeasonDetails(id, season, t.options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s := &Season{
+		ShowId:       id,
+		SeasonNumber: detail.SeasonNumber,
+		Name:         detail.Name,
+		Overview:     detail.Overview,
+		AirDate:      detail.AirDate,
+		EpisodeCount: len(detail.Episodes),
+		Episodes:     make([]*Episode, 0, len(detail.Episodes)),
+	}
+	for _, e := range detail.Episodes {
+		s.Episodes = append(s.Episodes, &Episode{
+			Id:            int(e.ID),
+			ShowId:        id,
+			SeasonNumber:  e.SeasonNumber,
+			EpisodeNumber: e.EpisodeNumber,
+			Name:          e.Name,
+			Overview:      e.Overview,
+			AirDate:       e.AirDate,
+		})
+	}
+	return s, nil
+}
+
+// GetEpisodeDetail 集详情
+func (t *Tmdb) GetEpisodeDetail(id int, season int, episode int) (*Episode, error) {
+	key := t.cacheKey(TmdbCacheEpisode, strconv.Itoa(id)+":"+strconv.Itoa(season)+":"+strconv.Itoa(episode))
+	detail, err := tmdbLoad(t, key, t.searchTTL, func() (*tmdb.TVEpisodeDetails, error) {
+		return t.client.GetTVEpisodeDetails(id, season, episode, t.options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Episode{
+		Id:            int(detail.ID),
+		ShowId:        id,
+		SeasonNumber:  detail.SeasonNumber,
+		EpisodeNumber: detail.EpisodeNumber,
+		Name:          detail.Name,
+		Overview:      detail.Overview,
+		AirDate:       detail.AirDate,
+		Runtime:       detail.Runtime,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package media
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testSeason() *Season {
+	return &Season{
+		ShowId:       215803,
+		SeasonNumber: 1,
+		Episodes: []*Episode{
+			{EpisodeNumber: 1, AirDate: "2023-01-14"},
+			{EpisodeNumber: 2, AirDate: "2023-01-14"},
+			{EpisodeNumber: 3, AirDate: "2023-01-15"},
+			{EpisodeNumber: 4, AirDate: "2023-01-16"},
+			{EpisodeNumber: 5},
+		},
+	}
+}
+
+func episodeNumbers(episodes []*Episode) []int {
+	numbers := make([]int, 0, len(episodes))
+	for _, e := range episodes {
+		numbers = append(numbers, e.EpisodeNumber)
+	}
+	return numbers
+}
+
+func TestSeasonGetEpisode(t *testing.T) {
+	s := testSeason()
+	if e := s.GetEpisode(3); e == nil || e.AirDate != "2023-01-15" {
+		t.Errorf("GetEpisode(3) = %+v", e)
+	}
+	if e := s.GetEpisode(6); e != nil {
+		t.Errorf("GetEpisode(6) = %+v, want nil", e)
+	}
+	if s.IsSpecial() || !(&Season{}).IsSpecial() {
+		t.Errorf("IsSpecial only for season 0")
+	}
+}
+
+func TestSeasonFindByAirDate(t *testing.T) {
+	tests := map[string][]int{
+		"2023-01-14": {1, 2},
+		"2023-01-15": {3},
+		"2023-01-17": {},
+		"":           {},
+	}
+	s := testSeason()
+	for date, want := range tests {
+		if got := episodeNumbers(s.FindByAirDate(date)); !reflect.DeepEqual(got, want) {
+			t.Errorf("FindByAirDate(%q) = %v, want %v", date, got, want)
+		}
+	}
+}
+
+func TestSeasonAiredEpisodes(t *testing.T) {
+	tests := map[string][]int{
+		"2023-01-13": {},
+		"2023-01-14": {1, 2},
+		"2023-01-15": {1, 2, 3},
+		"2024-01-01": {1, 2, 3, 4},
+	}
+	s := testSeason()
+	for date, want := range tests {
+		if got := episodeNumbers(s.AiredEpisodes(date)); !reflect.DeepEqual(got, want) {
+			t.Errorf("AiredEpisodes(%q) = %v, want %v", date, got, want)
+		}
+	}
+}
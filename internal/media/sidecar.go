@@ -0,0 +1,134 @@
+package media
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	FileKindUnknown  = iota
+	FileKindVideo    // 正片
+	FileKindSubtitle // 字幕
+	FileKindSample   // 样片
+	FileKindTrailer  // 预告片
+	FileKindExtra    // 花絮、删减片段、访谈等
+	FileKindNfo      // NFO
+	FileKindArtwork  // 海报、背景图等
+)
+
+var (
+	SubtitleExt = [...]string{".srt", ".ass", ".ssa", ".sup", ".idx", ".sub", ".vtt", ".smi"}
+	ArtworkExt  = [...]string{".jpg", ".jpeg", ".png", ".webp", ".tbn", ".gif"}
+	NfoExt      = [...]string{".nfo"}
+)
+
+var (
+	SampleRe            = regexp.MustCompile(`(?i)(?:^|[.\-_\s\[(])sample(?:[.\-_\s\])]|$)`)
+	TrailerRe           = regexp.MustCompile(`(?i)(?:^|[.\-_\s\[(])(?:trailer|teaser|预告片?)\d*(?:[.\-_\s\])]|$)`)
+	ExtraRe             = regexp.MustCompile(`(?i)-(?:featurette|behindthescenes|deleted|interview|scene|short|other|extra)s?$`)
+	SampleDirRe         = regexp.MustCompile(`(?i)^samples?$`)
+	TrailerDirRe        = regexp.MustCompile(`(?i)^(?:trailers?|预告片?)$`)
+	ExtraDirRe          = regexp.MustCompile(`(?i)^(?:extras?|featurettes?|behind the scenes|deleted scenes|interviews?|scenes|shorts|other|bonus|花絮|特典)$`)
+	SubtitleForceRe     = regexp.MustCompile(`(?i)^(?:forced|default|sdh|hi|cc)$`)
+	SubtitleLangSplitRe = regexp.MustCompile(`[&+_]`)
+)
+
+// FileInfo 文件分类结果
+type FileInfo struct {
+	Kind     int    `json:"kind"`
+	Language string `json:"language,omitempty"` // 字幕语言代码
+	Forced   bool   `json:"forced,omitempty"`   // 强制字幕
+	SDH      bool   `json:"sdh,omitempty"`      // 听障字幕
+}
+
+// IsSubtitleFile 是否字幕文件
+func IsSubtitleFile(f string) bool {
+	return hasExt(f, SubtitleExt[:])
+}
+
+// IsArtworkFile 是否图片文件
+func IsArtworkFile(f string) bool {
+	return hasExt(f, ArtworkExt[:])
+}
+
+// IsNfoFile 是否 NFO 文件
+func IsNfoFile(f string) bool {
+	return hasExt(f, NfoExt[:])
+}
+
+func hasExt(f string, exts []string) bool {
+	ext := strings.ToLower(path.Ext(f))
+	for _, e := range exts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyFile 根据文件名和所在目录对文件分类
+func ClassifyFile(filePath string) FileInfo {
+	filePath = strings.ReplaceAll(filePath, "\\", "/")
+	dir, file := path.Split(filePath)
+	name := strings.TrimSuffix(file, path.Ext(file))
+	parent := path.Base(strings.TrimSuffix(dir, "/"))
+	switch {
+	case IsSubtitleFile(file):
+		return parseSubtitleFile(name)
+	case IsNfoFile(file):
+		return FileInfo{Kind: FileKindNfo}
+	case IsArtworkFile(file):
+		return FileInfo{Kind: FileKindArtwork}
+	case !IsMediaFile(file):
+		return FileInfo{Kind: FileKindUnknown}
+	case SampleRe.MatchString(name) || SampleDirRe.MatchString(parent):
+		return FileInfo{Kind: FileKindSample}
+	case TrailerRe.MatchString(name) || TrailerDirRe.MatchString(parent):
+		return FileInfo{Kind: FileKindTrailer}
+	case ExtraRe.MatchString(name) || ExtraDirRe.MatchString(parent):
+		return FileInfo{Kind: FileKindExtra}
+	}
+	return FileInfo{Kind: FileKindVideo}
+}
+
+// parseSubtitleFile 从文件名结尾的标签识别字幕语言，如 Movie.chs.forced.srt
+func parseSubtitleFile(name string) FileInfo {
+	info := FileInfo{Kind: FileKindSubtitle}
+	tags := strings.Split(name, ".")
+	for i := len(tags) - 1; i > 0; i-- {
+		tag := strings.ToLower(tags[i])
+		if SubtitleForceRe.MatchString(tag) {
+			switch tag {
+			case "forced":
+				info.Forced = true
+			case "sdh", "hi", "cc":
+				info.SDH = true
+			}
+			continue
+		}
+		// chs&eng、简英双语等取第一个语言
+		for _, t := range SubtitleLangSplitRe.Split(tag, -1) {
+			if lang := LanguageCode(t); lang != "" {
+				info.Language = lang
+				return info
+			}
+		}
+		break
+	}
+	return info
+}
+
+// IsSidecarOf 判断 sidecar 是否属于视频 video，要求在同一目录且文件名以视频文件名开头
+func IsSidecarOf(video, sidecar string) bool {
+	video = strings.ReplaceAll(video, "\\", "/")
+	sidecar = strings.ReplaceAll(sidecar, "\\", "/")
+	vdir, vfile := path.Split(video)
+	sdir, sfile := path.Split(sidecar)
+	if vdir != sdir || vfile == sfile {
+		return false
+	}
+	base := strings.TrimSuffix(vfile, path.Ext(vfile))
+	name := strings.TrimSuffix(sfile, path.Ext(sfile))
+	return name == base || strings.HasPrefix(name, base+".") || strings.HasPrefix(name, base+"-")
+}
@@ -0,0 +1,57 @@
+package media
+
+import "testing"
+
+func TestClassifyFile(t *testing.T) {
+	dir := "/media/Inception (2010)/"
+	tests := []struct {
+		path string
+		want FileInfo
+	}{
+		{dir + "Inception.2010.1080p.mkv", FileInfo{Kind: FileKindVideo}},
+		{"/media/Samsara.2011.1080p.mkv", FileInfo{Kind: FileKindVideo}},
+		{dir + "Inception.2010.1080p.chs.srt", FileInfo{Kind: FileKindSubtitle, Language: "zh-Hans"}},
+		{dir + "Inception.2010.1080p.简体.ass", FileInfo{Kind: FileKindSubtitle, Language: "zh-Hans"}},
+		{dir + "Inception.2010.1080p.chs&eng.ass", FileInfo{Kind: FileKindSubtitle, Language: "zh-Hans"}},
+		{dir + "Inception.2010.1080p.zh-Hans.forced.ass", FileInfo{Kind: FileKindSubtitle, Language: "zh-Hans", Forced: true}},
+		{dir + "Inception.2010.1080p.eng.sdh.srt", FileInfo{Kind: FileKindSubtitle, Language: "en", SDH: true}},
+		{dir + "Inception.2010.1080p.srt", FileInfo{Kind: FileKindSubtitle}},
+		{`C:\media\Inception\Inception.chs.srt`, FileInfo{Kind: FileKindSubtitle, Language: "zh-Hans"}},
+		{dir + "Inception.2010.1080p.nfo", FileInfo{Kind: FileKindNfo}},
+		{dir + "poster.jpg", FileInfo{Kind: FileKindArtwork}},
+		{dir + "Inception.2010.1080p-sample.mkv", FileInfo{Kind: FileKindSample}},
+		{dir + "Sample/inception.mkv", FileInfo{Kind: FileKindSample}},
+		{dir + "Inception-trailer.mp4", FileInfo{Kind: FileKindTrailer}},
+		{dir + "Trailers/t1.mp4", FileInfo{Kind: FileKindTrailer}},
+		{dir + "Inception-featurette.mkv", FileInfo{Kind: FileKindExtra}},
+		{dir + "Extras/making of.mkv", FileInfo{Kind: FileKindExtra}},
+		{dir + "花絮/making of.mkv", FileInfo{Kind: FileKindExtra}},
+		{dir + "readme.txt", FileInfo{Kind: FileKindUnknown}},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFile(tt.path); got != tt.want {
+			t.Errorf("ClassifyFile(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsSidecarOf(t *testing.T) {
+	video := "/media/Inception (2010)/Inception.2010.1080p.mkv"
+	tests := []struct {
+		sidecar string
+		want    bool
+	}{
+		{"/media/Inception (2010)/Inception.2010.1080p.chs.srt", true},
+		{"/media/Inception (2010)/Inception.2010.1080p.nfo", true},
+		{"/media/Inception (2010)/Inception.2010.1080p-poster.jpg", true},
+		{"/media/Inception (2010)/Inception.2010.1080p.5.1.srt", true},
+		{"/media/Inception (2010)/Inception.2010.720p.chs.srt", false},
+		{"/media/Inception (2010)/Inception.2010.1080pX.srt", false},
+		{"/media/Other/Inception.2010.1080p.chs.srt", false},
+	}
+	for _, tt := range tests {
+		if got := IsSidecarOf(video, tt.sidecar); got != tt.want {
+			t.Errorf("IsSidecarOf(%q) = %v, want %v", tt.sidecar, got, tt.want)
+		}
+	}
+}
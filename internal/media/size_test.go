@@ -0,0 +1,65 @@
+package media
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1.5GB":   3 << 29,
+		"700 MB":  700 << 20,
+		"4.37GiB": 4692251770,
+		"2TB":     2 << 40,
+		"512kb":   512 << 10,
+		" 8GB ":   8 << 30,
+		"12":      0,
+		"GB":      0,
+		"1,5GB":   0,
+	}
+	for text, want := range tests {
+		if got := ParseSize(text); got != want {
+			t.Errorf("ParseSize(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestParseTitleSize(t *testing.T) {
+	tests := []struct {
+		title, name, encode string
+		size                int64
+	}{
+		{"Inception.2010.1080p.BluRay.x264 12.5GB", "Inception", "x264", 25 << 29},
+		{"Movie.2019.1080p.WEB-DL.4.37GiB.mkv", "Movie", "", 4692251770},
+		{"Movie 2019 1080p 700MB", "Movie", "", 700 << 20},
+		{"[SubsPlease] One Piece - 1071 (1080p) [1.4GB].mkv", "One Piece", "", 1503238553},
+		{"Movie.2019.1080p.mkv", "Movie", "", 0},
+		// 大小前面紧跟字母数字时不从编码等中间截取
+		{"Movie.Name.2020.1080p.BluRay.x264.10GB-GRP", "Movie Name", "x264", 10 << 30},
+		{"Movie.Name.2020.1080p.BluRay.x26410GB", "Movie Name", "", 0},
+	}
+	for _, tt := range tests {
+		m := NewMeta(tt.title, "", MediaUnknown, false).GetMeta()
+		if m.GetName() != tt.name || m.Size != tt.size || tt.encode != "" && !strings.EqualFold(m.VideoEncode, tt.encode) {
+			t.Errorf("NewMeta(%q) = %q %q %d, want %q %q %d", tt.title, m.GetName(), m.VideoEncode, m.Size, tt.name, tt.encode, tt.size)
+		}
+	}
+}
+
+func TestGetSizePerMinute(t *testing.T) {
+	tests := []struct {
+		size    int64
+		runtime int
+		want    int64
+	}{
+		{120 << 20, 120, 1 << 20},
+		{120 << 20, 0, 0},
+		{0, 120, 0},
+	}
+	for _, tt := range tests {
+		m := &Meta{Size: tt.size, Runtime: tt.runtime}
+		if got := m.GetSizePerMinute(); got != tt.want {
+			t.Errorf("GetSizePerMinute(%d, %d) = %d, want %d", tt.size, tt.runtime, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	StepPart        = "part"
+	StepAirDate     = "air_date"
+	StepEdition     = "edition"
+	StepLanguage    = "language"
+	StepName        = "name"
+	StepYear        = "year"
+	StepPix         = "pix"
+	StepSeason      = "season"
+	StepEpisode     = "episode"
+	StepSource      = "source"
+	StepVideoEncode = "video_encode"
+	StepAudioEncode = "audio_encode"
+)
+
+var (
+	ErrStepName     = errors.New("step name is empty")
+	ErrStepExists   = errors.New("step already registered")
+	ErrStepNotFound = errors.New("step not found")
+	ErrStepRepeated = errors.New("step repeated")
+	ErrStepField    = errors.New("meta field not supported")
+)
+
+// StepFactory 为每次解析创建 Step
+type StepFactory func(m *Meta) Step
+
+type stepRegistry struct {
+	sync.RWMutex
+	factories map[string]StepFactory
+	order     []string
+}
+
+var steps = newStepRegistry()
+
+func newStepRegistry() *stepRegistry {
+	r := &stepRegistry{
+		factories: make(map[string]StepFactory),
+	}
+	r.add(StepPart, NewParsePart)
+	r.add(StepAirDate, NewParseAirDate)
+	r.add(StepEdition, NewParseEdition)
+	r.add(StepLanguage, NewParseLanguage)
+	r.add(StepName, NewParseName)
+	r.add(StepYear, NewParseYear)
+	r.add(StepPix, NewParseResourcePix)
+	r.add(StepSeason, NewParseSeason)
+	r.add(StepEpisode, NewParseEpisode)
+	r.add(StepSource, NewParseResourceType)
+	r.add(StepVideoEncode, NewParseVideoEncode)
+	r.add(StepAudioEncode, NewParseAudioEncode)
+	return r
+}
+
+func (r *stepRegistry) add(name string, factory StepFactory) {
+	r.factories[name] = factory
+	r.order = append(r.order, name)
+}
+
+func (r *stepRegistry) indexOf(name string) int {
+	for i, n := range r.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// RegisterStep 注册 Step 并插入到 before 之前，before 为空时追加到末尾
+func RegisterStep(name string, factory StepFactory, before string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrStepName
+	}
+	steps.Lock()
+	defer steps.Unlock()
+	if _, ok := steps.factories[name]; ok {
+		return fmt.Errorf("%w: %s", ErrStepExists, name)
+	}
+	pos := len(steps.order)
+	if before != "" {
+		if pos = steps.indexOf(before); pos < 0 {
+			return fmt.Errorf("%w: %s", ErrStepNotFound, before)
+		}
+	}
+	steps.factories[name] = factory
+	steps.order = append(steps.order[:pos], append([]string{name}, steps.order[pos:]...)...)
+	return nil
+}
+
+// SetStepOrder 设置 Step 顺序，未注册或重复的 Step 返回错误，未列出的 Step 不参与解析并记录警告
+func SetStepOrder(order []string) error {
+	steps.Lock()
+	defer steps.Unlock()
+	listed := make(map[string]bool, len(order))
+	for _, name := range order {
+		if _, ok := steps.factories[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrStepNotFound, name)
+		}
+		if listed[name] {
+			return fmt.Errorf("%w: %s", ErrStepRepeated, name)
+		}
+		listed[name] = true
+	}
+	var disabled []string
+	for name := range steps.factories {
+		if !listed[name] {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) > 0 {
+		sort.Strings(disabled)
+		log.Warnf("parse steps %s not in order, disabled", strings.Join(disabled, ","))
+	}
+	steps.order = append([]string(nil), order...)
+	return nil
+}
+
+// StepOrder 当前 Step 顺序
+func StepOrder() []string {
+	steps.RLock()
+	defer steps.RUnlock()
+	return append([]string(nil), steps.order...)
+}
+
+// NewSteps 按当前顺序创建解析 Step
+func NewSteps(m *Meta) []Step {
+	steps.RLock()
+	defer steps.RUnlock()
+	s := make([]Step, 0, len(steps.order))
+	for _, name := range steps.order {
+		s = append(s, steps.factories[name](m))
+	}
+	return s
+}
+
+// RegexStepConfig 配置定义的正则 Step，匹配 token 后写入 Meta 字段
+type RegexStepConfig struct {
+	Name   string `json:"name"`
+	Regex  string `json:"regex"`
+	Field  string `json:"field"`  // Meta 字段名，支持 string、int、[]string
+	Value  string `json:"value"`  // 写入的值，支持 $1 引用分组，为空时使用匹配内容
+	Tokens int    `json:"tokens"` // 参与匹配的 token 数，多个 token 以空格连接，默认 1
+	Before string `json:"before"` // 插入到该 Step 之前，为空追加到末尾
+}
+
+// RegisterRegexStep 注册配置定义的正则 Step
+func RegisterRegexStep(c RegexStepConfig) error {
+	re, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return err
+	}
+	if err = checkMetaField(c.Field); err != nil {
+		return err
+	}
+	if c.Tokens < 1 {
+		c.Tokens = 1
+	}
+	return RegisterStep(c.Name, func(m *Meta) Step {
+		return &RegexStep{
+			ParseStep: NewParseStep(m),
+			name:      c.Name,
+			re:        re,
+			field:     c.Field,
+			value:     c.Value,
+			tokens:    c.Tokens,
+		}
+	}, c.Before)
+}
+
+type RegexStep struct {
+	*ParseStep
+	name   string
+	re     *regexp.Regexp
+	field  string
+	value  string
+	tokens int
+}
+
+func (p *RegexStep) Name() string {
+	return p.name
+}
+
+func (p *RegexStep) Run(ctx *Parser, token string) bool {
+	text := token
+	next := make([]string, 0, p.tokens-1)
+	for i := 1; i < p.tokens; i++ {
+		t, err := ctx.token.PeekN(i)
+		if err != nil {
+			return true
+		}
+		next = append(next, t)
+	}
+	if len(next) > 0 {
+		text = fmt.Sprintf("%s %s", token, strings.Join(next, " "))
+	}
+	match := p.re.FindStringSubmatchIndex(text)
+	if match == nil {
+		return true
+	}
+	value := text[match[0]:match[1]]
+	if p.value != "" {
+		value = string(p.re.ExpandString(nil, p.value, text, match))
+	}
+	setMetaField(p.meta, p.field, value)
+	// 跳过参与匹配的后续 token
+	for range next {
+		ctx.token.Next()
+	}
+	ctx.SetFlag(ParseFlagName)
+	return false
+}
+
+func checkMetaField(field string) error {
+	f, ok := reflect.TypeOf(Meta{}).FieldByName(field)
+	if !ok || !f.IsExported() {
+		return fmt.Errorf("%w: %s", ErrStepField, field)
+	}
+	switch f.Type.Kind() {
+	case reflect.String, reflect.Int:
+		return nil
+	case reflect.Slice:
+		if f.Type.Elem().Kind() == reflect.String {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrStepField, field)
+}
+
+// setMetaField 写入 Meta 字段，string、int 未设置时写入，[]string 去重追加
+func setMetaField(m *Meta, field, value string) {
+	v := reflect.ValueOf(m).Elem().FieldByName(field)
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			v.SetString(value)
+		}
+	case reflect.Int:
+		if n, err := strconv.Atoi(value); err == nil && v.Int() == 0 {
+			v.SetInt(int64(n))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if v.Index(i).String() == value {
+				return
+			}
+		}
+		v.Set(reflect.Append(v, reflect.ValueOf(value)))
+	}
+}
@@ -0,0 +1,102 @@
+package media
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSetStepOrder(t *testing.T) {
+	defer func(order []string) {
+		steps.Lock()
+		steps.order = order
+		steps.Unlock()
+	}(StepOrder())
+
+	tests := []struct {
+		order []string
+		err   error
+	}{
+		{[]string{StepName, StepYear, "unknown"}, ErrStepNotFound},
+		{[]string{StepName, StepYear, StepName}, ErrStepRepeated},
+		{[]string{StepYear, StepName}, nil},
+	}
+	for _, tt := range tests {
+		before := StepOrder()
+		err := SetStepOrder(tt.order)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("SetStepOrder(%q) error %v, want %v", tt.order, err, tt.err)
+			continue
+		}
+		want := tt.order
+		if err != nil {
+			want = before
+		}
+		if got := StepOrder(); !reflect.DeepEqual(got, want) {
+			t.Errorf("SetStepOrder(%q) order %q, want %q", tt.order, got, want)
+		}
+	}
+}
+
+func TestRegisterStep(t *testing.T) {
+	defer func(order []string) {
+		steps.Lock()
+		steps.order = order
+		delete(steps.factories, "test_tag")
+		steps.Unlock()
+	}(StepOrder())
+
+	if err := RegisterStep(StepName, NewParseName, ""); !errors.Is(err, ErrStepExists) {
+		t.Errorf("register existing step error %v, want %v", err, ErrStepExists)
+	}
+	if err := RegisterStep("test_tag", NewParseName, "missing"); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("register before missing step error %v, want %v", err, ErrStepNotFound)
+	}
+	if err := RegisterStep("test_tag", NewParseName, StepYear); err != nil {
+		t.Fatalf("register step failed, %s", err.Error())
+	}
+	order := StepOrder()
+	for i, name := range order {
+		if name == StepYear {
+			if i == 0 || order[i-1] != "test_tag" {
+				t.Errorf("step order %q, want test_tag before %s", order, StepYear)
+			}
+		}
+	}
+}
+
+func TestRegisterRegexStep(t *testing.T) {
+	defer func(order []string) {
+		steps.Lock()
+		steps.order = order
+		delete(steps.factories, "test_web_tag")
+		steps.Unlock()
+	}(StepOrder())
+
+	if err := RegisterRegexStep(RegexStepConfig{Name: "bad_field", Regex: "x", Field: "Unknown"}); !errors.Is(err, ErrStepField) {
+		t.Errorf("register unknown field error %v, want %v", err, ErrStepField)
+	}
+	if err := RegisterRegexStep(RegexStepConfig{Name: "bad_regex", Regex: "(", Field: "Edition"}); err == nil {
+		t.Errorf("register invalid regex succeeded")
+	}
+	if err := RegisterRegexStep(RegexStepConfig{Name: " ", Regex: "x", Field: "Edition"}); !errors.Is(err, ErrStepName) {
+		t.Errorf("register empty name error %v, want %v", err, ErrStepName)
+	}
+	err := RegisterRegexStep(RegexStepConfig{
+		Name:   "test_web_tag",
+		Regex:  `(?i)^NF$`,
+		Field:  "ReleaseGroup",
+		Value:  "Netflix",
+		Before: StepName,
+	})
+	if err != nil {
+		t.Fatalf("register regex step failed, %s", err.Error())
+	}
+	m := NewMeta("Show.S01E02.NF.1080p.WEB-DL", "", MediaUnknown, false).GetMeta()
+	if m.ReleaseGroup != "Netflix" {
+		t.Errorf("ReleaseGroup = %q, want Netflix", m.ReleaseGroup)
+	}
+	if m.GetName() != "Show" {
+		t.Errorf("name = %q, want Show", m.GetName())
+	}
+}
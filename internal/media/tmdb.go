@@ -1,24 +1,59 @@
 package media
 
 import (
-	"fmt"
 	tmdb "github.com/cyruzin/golang-tmdb"
 	"github.com/hashicorp/golang-lru/arc/v2"
 	log "github.com/sirupsen/logrus"
+	"mediahub/internal/utils"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Tmdb struct {
-	client      *tmdb.Client
-	options     map[string]string
-	movieCache  *arc.ARCCache[int, *tmdb.MovieDetails]
-	tvCache     *arc.ARCCache[int, *tmdb.TVDetails]
-	searchCache *arc.ARCCache[string, *tmdb.SearchMulti]
+	client    *tmdb.Client
+	options   map[string]string
+	cache     *arc.ARCCache[string, tmdbEntry]
+	store     TmdbStore     // 持久化缓存，为 nil 时只缓存在内存
+	searchTTL time.Duration // 搜索结果有效期
+	detailTTL time.Duration // 详情有效期
+	offline   bool          // 离线模式，只使用缓存，不请求 TMDB
+	flight    utils.Flight  // 合并相同 key 的并发请求
+	limiter   *utils.RateLimiter
+	stats     tmdbStats
 }
 
-func NewTmdb(apiKey string, language string, proxyUrl string) *Tmdb {
+// TmdbApiURL golang-tmdb 请求的接口地址，自定义地址时替换这一前缀
+const TmdbApiURL = "https://api.themoviedb.org/3"
+
+type TmdbOption func(opt *tmdbOptions)
+
+type tmdbOptions struct {
+	baseURL   string
+	transport http.RoundTripper
+}
+
+// WithTmdbBaseURL 使用自定义接口地址，如测试用的假 TMDB 服务
+func WithTmdbBaseURL(baseURL string) TmdbOption {
+	return func(opt *tmdbOptions) {
+		opt.baseURL = baseURL
+	}
+}
+
+// WithTmdbTransport 使用自定义 http.RoundTripper，如录制回放，设置后忽略代理
+func WithTmdbTransport(transport http.RoundTripper) TmdbOption {
+	return func(opt *tmdbOptions) {
+		opt.transport = transport
+	}
+}
+
+func NewTmdb(apiKey string, language string, proxyUrl string, opts ...TmdbOption) *Tmdb {
+	opt := new(tmdbOptions)
+	for _, o := range opts {
+		o(opt)
+	}
 	tmdbClient, err := tmdb.Init(apiKey)
 	if err != nil {
 		log.Fatalf("create tmdb failed, err %s", err.Error())
@@ -28,89 +63,216 @@ func NewTmdb(apiKey string, language string, proxyUrl string) *Tmdb {
 		language = "zh"
 	}
 	options["language"] = language
-	var proxy func(*http.Request) (*url.URL, error)
-	if proxyUrl != "" {
-		proxyUrl_, err := url.Parse(proxyUrl)
-		if err != nil {
-			log.Errorf("parse proxy url failed, %s", err.Error())
-		} else {
-			proxy = http.ProxyURL(proxyUrl_)
-		}
-	}
 	tmdbClient.SetClientAutoRetry()
-	tmdbClient.SetClientConfig(http.Client{
-		Timeout: time.Second * 5,
-		Transport: &http.Transport{
-			Proxy:           proxy,
-			MaxIdleConns:    10,
-			IdleConnTimeout: 15 * time.Second,
-		},
-	})
-	detailCache, err := arc.NewARC[int, *tmdb.MovieDetails](512)
-	if err != nil {
-		log.Fatal(err.Error())
+	httpClient := newHttpClient(proxyUrl)
+	if opt.transport != nil {
+		httpClient.Transport = opt.transport
 	}
-	searchCache, err := arc.NewARC[string, *tmdb.SearchMulti](512)
-	if err != nil {
-		log.Fatal(err.Error())
+	if opt.baseURL != "" {
+		base, err := url.Parse(strings.TrimSuffix(opt.baseURL, "/"))
+		if err != nil {
+			log.Fatalf("parse tmdb base url failed, %s", err.Error())
+		}
+		httpClient.Transport = &rewriteTransport{base: base, next: httpClient.Transport}
 	}
-	tvCache, err := arc.NewARC[int, *tmdb.TVDetails](512)
+	tmdbClient.SetClientConfig(httpClient)
+	cache, err := arc.NewARC[string, tmdbEntry](1536)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 	return &Tmdb{
-		client:      tmdbClient,
-		options:     options,
-		movieCache:  detailCache,
-		searchCache: searchCache,
-		tvCache:     tvCache,
+		client:    tmdbClient,
+		options:   options,
+		cache:     cache,
+		searchTTL: DefaultSearchTTL,
+		detailTTL: DefaultDetailTTL,
 	}
 }
 
-func (t *Tmdb) QueryByName(name string) (err error) {
-	search, ok := t.searchCache.Get(name)
-	if !ok {
-		search, err = t.client.GetSearchMulti(name, t.options)
+// rewriteTransport 把 TmdbApiURL 开头的请求转发到 base
+type rewriteTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (r *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := req.URL.String()
+	if strings.HasPrefix(u, TmdbApiURL) {
+		target, err := url.Parse(r.base.String() + strings.TrimPrefix(u, TmdbApiURL))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		t.searchCache.Add(name, search)
+		req = req.Clone(req.Context())
+		req.URL = target
+		req.Host = target.Host
 	}
+	return r.next.RoundTrip(req)
+}
 
-	for _, result := range search.Results {
-		if result.MediaType == "movie" {
-			fmt.Println(result.MediaType, result.Title, result.ReleaseDate)
-		} else if result.MediaType == "tv" {
-			fmt.Println(result.MediaType, result.Name, result.FirstAirDate)
-		}
+// QueryByName 按名称搜索电影和电视剧
+func (t *Tmdb) QueryByName(name string) (*tmdb.SearchMulti, error) {
+	return tmdbLoad(t, t.cacheKey(TmdbCacheSearch, name), t.searchTTL, func() (*tmdb.SearchMulti, error) {
+		return t.client.GetSearchMulti(name, t.options)
+	})
+}
+
+// GetMovieDetail 获取电影详情，附带外部 ID 和其他标题
+func (t *Tmdb) GetMovieDetail(id int) (*tmdb.MovieDetails, error) {
+	return tmdbLoad(t, t.cacheKey(TmdbCacheMovie, strconv.Itoa(id)), t.detailTTL, func() (*tmdb.MovieDetails, error) {
+		return t.client.GetMovieDetails(id, t.withOptions("append_to_response", "external_ids,alternative_titles"))
+	})
+}
+
+// GetTvDetail 获取电视剧详情，附带外部 ID 和其他标题
+func (t *Tmdb) GetTvDetail(id int) (*tmdb.TVDetails, error) {
+	return tmdbLoad(t, t.cacheKey(TmdbCacheTv, strconv.Itoa(id)), t.detailTTL, func() (*tmdb.TVDetails, error) {
+		return t.client.GetTVDetails(id, t.withOptions("append_to_response", "external_ids,alternative_titles"))
+	})
+}
+
+// withOptions 复制默认参数并追加 key、value
+func (t *Tmdb) withOptions(kv ...string) map[string]string {
+	options := make(map[string]string, len(t.options)+len(kv)/2)
+	for k, v := range t.options {
+		options[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		options[kv[i]] = kv[i+1]
 	}
-	return nil
+	return options
 }
 
-func (t *Tmdb) GetMovieDetail(id int) (err error) {
-	detail, ok := t.movieCache.Get(id)
-	if !ok {
-		detail, err = t.client.GetMovieDetails(id, t.options)
-		if err != nil {
-			return err
+func (t *Tmdb) Name() string {
+	return ProviderTmdb
+}
+
+// SearchMedia 实现 Provider
+func (t *Tmdb) SearchMedia(name string) ([]*SearchResult, error) {
+	search, err := t.QueryByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if search.SearchMultiResults == nil {
+		return nil, nil
+	}
+	results := make([]*SearchResult, 0, len(search.Results))
+	for _, r := range search.Results {
+		result := &SearchResult{Provider: ProviderTmdb, Id: strconv.FormatInt(r.ID, 10), Popularity: r.Popularity}
+		switch r.MediaType {
+		case "movie":
+			result.MediaType = MediaTypeMovie
+			result.Title, result.OriginalTitle = r.Title, r.OriginalTitle
+			result.Year = dateYear(r.ReleaseDate)
+		case "tv":
+			result.MediaType = MediaTypeTv
+			result.Title, result.OriginalTitle = r.Name, r.OriginalName
+			result.Year = dateYear(r.FirstAirDate)
+		default:
+			continue
 		}
-		t.movieCache.Add(id, detail)
+		results = append(results, result)
 	}
+	return results, nil
+}
 
-	log.Infof("movie:%s overview:%s", detail.Title, detail.Overview)
-	return nil
+// GetMovieInfo 实现 Provider
+func (t *Tmdb) GetMovieInfo(id string) (*MediaDetail, error) {
+	tmdbId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := t.GetMovieDetail(tmdbId)
+	if err != nil {
+		return nil, err
+	}
+	info := &MediaDetail{
+		Provider:      ProviderTmdb,
+		Id:            id,
+		MediaType:     MediaTypeMovie,
+		Title:         detail.Title,
+		OriginalTitle: detail.OriginalTitle,
+		Year:          dateYear(detail.ReleaseDate),
+		ReleaseDate:   detail.ReleaseDate,
+		Genres:        make([]string, 0, len(detail.Genres)),
+		GenreIds:      make([]int, 0, len(detail.Genres)),
+		Language:      detail.OriginalLanguage,
+		Countries:     make([]string, 0, len(detail.ProductionCountries)),
+		Overview:      detail.Overview,
+		Runtime:       detail.Runtime,
+		Rating:        detail.VoteAverage,
+		Ids:           ExternalIds{TmdbId: tmdbId, ImdbId: detail.IMDbID},
+	}
+	for _, g := range detail.Genres {
+		info.Genres = append(info.Genres, g.Name)
+		info.GenreIds = append(info.GenreIds, int(g.ID))
+	}
+	for _, c := range detail.ProductionCountries {
+		info.Countries = append(info.Countries, c.Iso3166_1)
+	}
+	if detail.MovieAlternativeTitlesAppend != nil && detail.AlternativeTitles != nil {
+		for _, a := range detail.AlternativeTitles.Titles {
+			info.AlsoKnownAs = append(info.AlsoKnownAs, a.Title)
+		}
+	}
+	return info, nil
 }
 
-func (t *Tmdb) GetTvDetail(id int) (err error) {
-	detail, ok := t.tvCache.Get(id)
-	if !ok {
-		detail, err = t.client.GetTVDetails(id, t.options)
-		if err != nil {
-			return err
+// GetTvInfo 实现 Provider
+func (t *Tmdb) GetTvInfo(id string) (*MediaDetail, error) {
+	tmdbId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := t.GetTvDetail(tmdbId)
+	if err != nil {
+		return nil, err
+	}
+	info := &MediaDetail{
+		Provider:      ProviderTmdb,
+		Id:            id,
+		MediaType:     MediaTypeTv,
+		Title:         detail.Name,
+		OriginalTitle: detail.OriginalName,
+		Year:          dateYear(detail.FirstAirDate),
+		ReleaseDate:   detail.FirstAirDate,
+		Genres:        make([]string, 0, len(detail.Genres)),
+		GenreIds:      make([]int, 0, len(detail.Genres)),
+		Language:      detail.OriginalLanguage,
+		Countries:     detail.OriginCountry,
+		Overview:      detail.Overview,
+		Rating:        detail.VoteAverage,
+		Ids:           ExternalIds{TmdbId: tmdbId},
+	}
+	if len(detail.EpisodeRunTime) > 0 {
+		info.Runtime = detail.EpisodeRunTime[0]
+	}
+	if detail.TVExternalIDsAppend != nil && detail.TVExternalIDs != nil {
+		info.Ids.ImdbId = detail.TVExternalIDs.IMDbID
+		info.Ids.TvdbId = int(detail.TVExternalIDs.TVDBID)
+	}
+	for _, g := range detail.Genres {
+		info.Genres = append(info.Genres, g.Name)
+		info.GenreIds = append(info.GenreIds, int(g.ID))
+	}
+	if len(info.Countries) == 0 {
+		for _, c := range detail.ProductionCountries {
+			info.Countries = append(info.Countries, c.Iso3166_1)
+		}
+	}
+	if detail.TVAlternativeTitlesAppend != nil && detail.AlternativeTitles != nil &&
+		detail.AlternativeTitles.TVAlternativeTitlesResults != nil {
+		for _, a := range detail.AlternativeTitles.Results {
+			info.AlsoKnownAs = append(info.AlsoKnownAs, a.Title)
 		}
-		t.tvCache.Add(id, detail)
 	}
+	return info, nil
+}
 
-	log.Infof("tv:%s overview:%s", detail.Name, detail.Overview)
-	return nil
+// GetExternalIds 实现 Provider，外部 ID 随详情一起获取
+func (t *Tmdb) GetExternalIds(id string, mediaType int) (*ExternalIds, error) {
+	detail, err := getDetail(t, id, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	return &detail.Ids, nil
 }
@@ -0,0 +1,203 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"mediahub/internal/utils"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	TmdbCacheSearch  = "search"
+	TmdbCacheMovie   = "movie"
+	TmdbCacheTv      = "tv"
+	TmdbCacheSeason  = "season"  // season:剧集ID:季
+	TmdbCacheEpisode = "episode" // episode:剧集ID:季:集
+	TmdbCacheFind    = "find"    // find:imdb_id-tt0111161
+)
+
+const (
+	DefaultSearchTTL = 24 * time.Hour
+	DefaultDetailTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrTmdbOffline      = errors.New("tmdb offline and not cached")
+	ErrUnknownTmdbCache = errors.New("unknown tmdb cache kind")
+	TmdbCacheKinds      = []string{TmdbCacheSearch, TmdbCacheMovie, TmdbCacheTv, TmdbCacheSeason, TmdbCacheEpisode, TmdbCacheFind}
+)
+
+// TmdbStore TMDB 数据的持久化缓存，key 形如 movie:123:zh
+type TmdbStore interface {
+	Load(key string) (data []byte, expiresAt time.Time, ok bool)
+	Save(key string, data []byte, expiresAt time.Time) error
+	Delete(prefix string) error
+}
+
+type tmdbEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// SetStore 设置持久化缓存
+func (t *Tmdb) SetStore(store TmdbStore) {
+	t.store = store
+}
+
+// SetTTL 设置搜索结果和详情的有效期，小于等于 0 时使用默认值
+func (t *Tmdb) SetTTL(search, detail time.Duration) {
+	if search <= 0 {
+		search = DefaultSearchTTL
+	}
+	if detail <= 0 {
+		detail = DefaultDetailTTL
+	}
+	t.searchTTL = search
+	t.detailTTL = detail
+}
+
+// SetOffline 离线模式下只使用缓存，过期的缓存也会返回
+func (t *Tmdb) SetOffline(offline bool) {
+	t.offline = offline
+}
+
+// Invalidate 删除缓存，id 为空时删除该类型全部缓存，kind 为空时删除全部缓存。
+// 季和集的 id 可以只写剧集 ID，删除该剧集全部季或集
+func (t *Tmdb) Invalidate(kind string, id string) error {
+	prefix := ""
+	if kind != "" {
+		if !isTmdbCacheKind(kind) {
+			return ErrUnknownTmdbCache
+		}
+		prefix = kind + ":"
+		if id != "" {
+			prefix += id + ":"
+		}
+	}
+	for _, key := range t.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			t.cache.Remove(key)
+		}
+	}
+	if t.store != nil {
+		return t.store.Delete(prefix)
+	}
+	return nil
+}
+
+func (t *Tmdb) cacheKey(kind string, id string) string {
+	return kind + ":" + id + ":" + t.options["language"]
+}
+
+// TmdbStats 缓存命中统计
+type TmdbStats struct {
+	Hits      int64 `json:"hits"`       // 内存缓存命中
+	StoreHits int64 `json:"store_hits"` // 持久化缓存命中
+	Misses    int64 `json:"misses"`     // 未命中或已过期，请求 TMDB
+	Stale     int64 `json:"stale"`      // 请求失败，使用过期缓存
+	Shared    int64 `json:"shared"`     // 与并发的相同请求合并
+	Errors    int64 `json:"errors"`     // 请求失败
+}
+
+type tmdbStats struct {
+	hits, storeHits, misses, stale, shared, errors atomic.Int64
+}
+
+// Stats 返回缓存命中统计
+func (t *Tmdb) Stats() TmdbStats {
+	return TmdbStats{
+		Hits:      t.stats.hits.Load(),
+		StoreHits: t.stats.storeHits.Load(),
+		Misses:    t.stats.misses.Load(),
+		Stale:     t.stats.stale.Load(),
+		Shared:    t.stats.shared.Load(),
+		Errors:    t.stats.errors.Load(),
+	}
+}
+
+// SetRateLimit 设置每秒请求数和突发请求数，rate 小于等于 0 时不限流
+func (t *Tmdb) SetRateLimit(rate float64, burst int) {
+	t.limiter = utils.NewRateLimiter(rate, burst)
+}
+
+// tmdbLoad 依次从内存、持久化缓存读取，过期后请求 TMDB；请求失败时返回过期的缓存。
+// 内存未命中时同一 key 的并发调用合并为一次
+func tmdbLoad[T any](t *Tmdb, key string, ttl time.Duration, fetch func() (*T, error)) (*T, error) {
+	var stale *T
+	if e, ok := t.cache.Get(key); ok {
+		if v, ok := e.value.(*T); ok {
+			if t.offline || time.Now().Before(e.expiresAt) {
+				t.stats.hits.Add(1)
+				return v, nil
+			}
+			stale = v
+		}
+	}
+	v, err, shared := t.flight.Do(key, func() (interface{}, error) {
+		return tmdbFetch(t, key, ttl, stale, fetch)
+	})
+	if shared {
+		t.stats.shared.Add(1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*T), nil
+}
+
+func tmdbFetch[T any](t *Tmdb, key string, ttl time.Duration, stale *T, fetch func() (*T, error)) (*T, error) {
+	now := time.Now()
+	if stale == nil && t.store != nil {
+		if data, expiresAt, ok := t.store.Load(key); ok {
+			v := new(T)
+			if err := json.Unmarshal(data, v); err != nil {
+				log.Errorf("decode tmdb cache %s failed, %s", key, err.Error())
+			} else {
+				t.cache.Add(key, tmdbEntry{value: v, expiresAt: expiresAt})
+				if t.offline || now.Before(expiresAt) {
+					t.stats.storeHits.Add(1)
+					return v, nil
+				}
+				stale = v
+			}
+		}
+	}
+	if t.offline {
+		return nil, ErrTmdbOffline
+	}
+	t.stats.misses.Add(1)
+	t.limiter.Wait()
+	v, err := fetch()
+	if err != nil {
+		t.stats.errors.Add(1)
+		if stale != nil {
+			t.stats.stale.Add(1)
+			log.Warnf("request tmdb %s failed, use stale cache, %s", key, err.Error())
+			return stale, nil
+		}
+		return nil, err
+	}
+	expiresAt := time.Now().Add(ttl)
+	t.cache.Add(key, tmdbEntry{value: v, expiresAt: expiresAt})
+	if t.store != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			log.Errorf("encode tmdb cache %s failed, %s", key, err.Error())
+		} else if err = t.store.Save(key, data, expiresAt); err != nil {
+			log.Errorf("save tmdb cache %s failed, %s", key, err.Error())
+		}
+	}
+	return v, nil
+}
+
+func isTmdbCacheKind(kind string) bool {
+	for _, k := range TmdbCacheKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package media
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore 内存中的 TmdbStore
+type memStore struct {
+	mu      sync.Mutex
+	entries map[string]memEntry
+}
+
+type memEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{entries: make(map[string]memEntry)}
+}
+
+func (s *memStore) Load(key string) ([]byte, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e.data, e.expiresAt, ok
+}
+
+func (s *memStore) Save(key string, data []byte, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memEntry{data: data, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *memStore) Delete(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// fetchCounter 返回固定详情或错误，记录请求次数
+type fetchCounter struct {
+	calls  int
+	detail MediaDetail
+	err    error
+}
+
+func (f *fetchCounter) fetch() (*MediaDetail, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	d := f.detail
+	return &d, nil
+}
+
+func TestTmdbLoad(t *testing.T) {
+	tm := NewTmdb("key", "zh", "")
+	store := newMemStore()
+	tm.SetStore(store)
+	f := &fetchCounter{detail: MediaDetail{Id: "27205", Title: "Inception"}}
+	key := tm.cacheKey(TmdbCacheMovie, "27205")
+
+	// 过期后重新请求
+	if d, err := tmdbLoad(tm, key, -time.Second, f.fetch); err != nil || d.Title != "Inception" {
+		t.Fatalf("tmdbLoad = %+v, %v", d, err)
+	}
+	f.detail.Title = "盗梦空间"
+	for i := 0; i < 2; i++ {
+		if d, err := tmdbLoad(tm, key, time.Hour, f.fetch); err != nil || d.Title != "盗梦空间" {
+			t.Fatalf("tmdbLoad = %+v, %v", d, err)
+		}
+	}
+	if _, _, ok := store.Load(key); f.calls != 2 || !ok {
+		t.Errorf("tmdbLoad fetched %d times, stored %v", f.calls, ok)
+	}
+
+	// 新的实例从持久化缓存读取
+	other := NewTmdb("key", "zh", "")
+	other.SetStore(store)
+	if d, err := tmdbLoad(other, key, time.Hour, f.fetch); err != nil || d.Title != "盗梦空间" || f.calls != 2 {
+		t.Errorf("tmdbLoad from store = %+v, %v, fetched %d times", d, err, f.calls)
+	}
+	if got, want := other.Stats(), (TmdbStats{StoreHits: 1}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+
+	// 请求失败时返回过期的缓存，没有缓存时返回错误
+	key = tm.cacheKey(TmdbCacheMovie, "1")
+	_, _ = tmdbLoad(tm, key, -time.Second, f.fetch)
+	f.err = errors.New("request failed")
+	if d, err := tmdbLoad(tm, key, time.Hour, f.fetch); err != nil || d.Title != "盗梦空间" {
+		t.Errorf("tmdbLoad stale = %+v, %v", d, err)
+	}
+	if _, err := tmdbLoad(tm, tm.cacheKey(TmdbCacheMovie, "2"), time.Hour, f.fetch); err != f.err {
+		t.Errorf("tmdbLoad error = %v, want %v", err, f.err)
+	}
+
+	if got, want := tm.Stats(), (TmdbStats{Hits: 1, Misses: 5, Stale: 1, Errors: 2}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestTmdbOfflineLoad(t *testing.T) {
+	tm := NewTmdb("key", "zh", "")
+	store := newMemStore()
+	tm.SetStore(store)
+	tm.SetOffline(true)
+	f := &fetchCounter{detail: MediaDetail{Title: "盗梦空间"}}
+	key := tm.cacheKey(TmdbCacheMovie, "27205")
+
+	if _, err := tmdbLoad(tm, key, time.Hour, f.fetch); !errors.Is(err, ErrTmdbOffline) || f.calls != 0 {
+		t.Errorf("tmdbLoad offline error %v, fetched %d times", err, f.calls)
+	}
+	// 离线时过期的缓存也返回
+	_ = store.Save(key, []byte(`{"title":"盗梦空间"}`), time.Now().Add(-time.Hour))
+	if d, err := tmdbLoad(tm, key, time.Hour, f.fetch); err != nil || d.Title != "盗梦空间" || f.calls != 0 {
+		t.Errorf("tmdbLoad offline = %+v, %v, fetched %d times", d, err, f.calls)
+	}
+}
+
+func TestTmdbInvalidate(t *testing.T) {
+	tm := NewTmdb("key", "zh", "")
+	store := newMemStore()
+	tm.SetStore(store)
+	keys := []string{
+		tm.cacheKey(TmdbCacheMovie, "1"),
+		tm.cacheKey(TmdbCacheMovie, "12"),
+		tm.cacheKey(TmdbCacheSeason, "1396:1"),
+		tm.cacheKey(TmdbCacheSeason, "1396:2"),
+		tm.cacheKey(TmdbCacheTv, "1396"),
+	}
+	load := func() {
+		for _, key := range keys {
+			f := &fetchCounter{}
+			_, _ = tmdbLoad(tm, key, time.Hour, f.fetch)
+		}
+	}
+	cached := func() []string {
+		var result []string
+		for _, key := range keys {
+			_, inMemory := tm.cache.Get(key)
+			_, _, inStore := store.Load(key)
+			if inMemory || inStore {
+				result = append(result, key)
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		kind, id string
+		left     int
+	}{
+		{TmdbCacheMovie, "1", 4},
+		{TmdbCacheSeason, "1396", 3},
+		{TmdbCacheMovie, "", 3},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		load()
+		if err := tm.Invalidate(tt.kind, tt.id); err != nil {
+			t.Errorf("Invalidate(%q, %q) failed, %s", tt.kind, tt.id, err.Error())
+		}
+		if left := cached(); len(left) != tt.left {
+			t.Errorf("Invalidate(%q, %q) left %q", tt.kind, tt.id, left)
+		}
+	}
+	if err := tm.Invalidate("person", ""); !errors.Is(err, ErrUnknownTmdbCache) {
+		t.Errorf("Invalidate unknown kind error %v, want %v", err, ErrUnknownTmdbCache)
+	}
+}
+
+func TestTmdbSetTTL(t *testing.T) {
+	tm := NewTmdb("key", "zh", "")
+	tm.SetTTL(time.Hour, 0)
+	if tm.searchTTL != time.Hour || tm.detailTTL != DefaultDetailTTL {
+		t.Errorf("SetTTL = %s %s", tm.searchTTL, tm.detailTTL)
+	}
+	tm.SetTTL(-1, 2*time.Hour)
+	if tm.searchTTL != DefaultSearchTTL || tm.detailTTL != 2*time.Hour {
+		t.Errorf("SetTTL = %s %s", tm.searchTTL, tm.detailTTL)
+	}
+}
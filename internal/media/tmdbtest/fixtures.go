@@ -0,0 +1,125 @@
+package tmdbtest
+
+// DefaultMovies 默认电影数据，名称相近的两部用于测试按年份区分
+var DefaultMovies = []Movie{
+	{
+		Id:            535167,
+		Title:         "流浪地球",
+		OriginalTitle: "流浪地球",
+		AlsoKnownAs:   []string{"The Wandering Earth"},
+		ReleaseDate:   "2019-02-05",
+		Overview:      "太阳即将毁灭，人类带着地球逃离太阳系。",
+		ImdbId:        "tt7605074",
+		Genres:        []string{"科幻", "冒险"},
+		Language:      "zh",
+		Countries:     []string{"CN"},
+		Runtime:       125,
+		Popularity:    30,
+		VoteAverage:   6.4,
+	},
+	{
+		Id:            842675,
+		Title:         "流浪地球2",
+		OriginalTitle: "流浪地球2",
+		AlsoKnownAs:   []string{"The Wandering Earth II"},
+		ReleaseDate:   "2023-01-22",
+		Overview:      "太阳即将毁灭，人类在地球表面建造出巨大的推进器。",
+		ImdbId:        "tt13539646",
+		Genres:        []string{"科幻", "动作"},
+		Language:      "zh",
+		Countries:     []string{"CN"},
+		Runtime:       173,
+		Popularity:    50,
+		VoteAverage:   7.2,
+	},
+	{
+		Id:            27205,
+		Title:         "盗梦空间",
+		OriginalTitle: "Inception",
+		ReleaseDate:   "2010-07-15",
+		Overview:      "造梦师进入他人梦境窃取秘密。",
+		ImdbId:        "tt1375666",
+		Genres:        []string{"动作", "科幻", "冒险"},
+		Language:      "en",
+		Countries:     []string{"US", "GB"},
+		Runtime:       148,
+		Popularity:    80,
+		VoteAverage:   8.4,
+	},
+}
+
+// DefaultShows 默认剧集数据，包含特别篇和跨年的多季，以及用于分类的动画
+var DefaultShows = []Show{
+	{
+		Id:           1396,
+		Name:         "绝命毒师",
+		OriginalName: "Breaking Bad",
+		FirstAirDate: "2008-01-20",
+		Overview:     "化学老师在确诊癌症后开始制毒。",
+		ImdbId:       "tt0903747",
+		TvdbId:       81189,
+		Genres:       []string{"剧情", "犯罪"},
+		Language:     "en",
+		Countries:    []string{"US"},
+		RunTime:      47,
+		Popularity:   200,
+		VoteAverage:  8.9,
+		Seasons: []Season{
+			{Number: 0, Name: "特别篇", AirDate: "2009-02-17", Episodes: []Episode{
+				{Number: 1, Name: "Good Cop Bad Cop", AirDate: "2009-02-17"},
+			}},
+			{Number: 1, Name: "第 1 季", AirDate: "2008-01-20", Episodes: []Episode{
+				{Number: 1, Name: "试播集", AirDate: "2008-01-20", Runtime: 58},
+				{Number: 2, Name: "猫在袋子里", AirDate: "2008-01-27", Runtime: 48},
+				{Number: 3, Name: "袋子在河里", AirDate: "2008-02-10", Runtime: 48},
+			}},
+			{Number: 2, Name: "第 2 季", AirDate: "2009-03-08", Episodes: []Episode{
+				{Number: 1, Name: "七三七", AirDate: "2009-03-08", Runtime: 47},
+				{Number: 2, Name: "灰色地带", AirDate: "2009-03-15", Runtime: 47},
+			}},
+		},
+	},
+	{
+		Id:           215803,
+		Name:         "狂飙",
+		OriginalName: "狂飙",
+		AlsoKnownAs:  []string{"The Knockout"},
+		FirstAirDate: "2023-01-14",
+		Overview:     "京海市警察与黑恶势力的二十年较量。",
+		Genres:       []string{"剧情", "犯罪"},
+		Language:     "zh",
+		Countries:    []string{"CN"},
+		RunTime:      45,
+		Popularity:   20,
+		VoteAverage:  8.3,
+		Seasons: []Season{
+			{Number: 1, Name: "第 1 季", AirDate: "2023-01-14", Episodes: []Episode{
+				{Number: 1, Name: "第 1 集", AirDate: "2023-01-14"},
+				{Number: 2, Name: "第 2 集", AirDate: "2023-01-14"},
+				{Number: 3, Name: "第 3 集", AirDate: "2023-01-15"},
+			}},
+		},
+	},
+	{
+		Id:           209867,
+		Name:         "葬送的芙莉莲",
+		OriginalName: "葬送のフリーレン",
+		AlsoKnownAs:  []string{"Frieren: Beyond Journey's End", "Frieren", "Sousou no Frieren"},
+		FirstAirDate: "2023-09-29",
+		Overview:     "打倒魔王后，精灵魔法使芙莉莲踏上了了解人类的旅程。",
+		ImdbId:       "tt22248376",
+		TvdbId:       424536,
+		Genres:       []string{"动画", "动作冒险", "剧情"},
+		Language:     "ja",
+		Countries:    []string{"JP"},
+		RunTime:      24,
+		Popularity:   80,
+		VoteAverage:  8.8,
+		Seasons: []Season{
+			{Number: 1, Name: "第 1 季", AirDate: "2023-09-29", Episodes: []Episode{
+				{Number: 1, Name: "冒险的结束", AirDate: "2023-09-29", Runtime: 24},
+				{Number: 2, Name: "不是魔法那样的东西", AirDate: "2023-09-29", Runtime: 24},
+			}},
+		},
+	},
+}
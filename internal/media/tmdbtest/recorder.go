@@ -0,0 +1,139 @@
+package tmdbtest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	ModeReplay = iota // 只读取录制的响应，没有录制时返回错误
+	ModeRecord        // 请求真实服务并保存响应
+)
+
+var (
+	ErrNotRecorded = errors.New("request not recorded")
+	// SecretParams 不参与文件名、也不写入文件的查询参数
+	SecretParams = []string{"api_key", "session_id"}
+	fileNameRe   = regexp.MustCompile(`[^0-9A-Za-z]+`)
+)
+
+// Recorder 录制回放 http.RoundTripper，每个请求保存为 Dir 下的一个 json 文件，
+// 文件名由方法、路径和去掉密钥的查询参数生成，录制后的文件可以提交到仓库。
+// 查询参数经过排序，参数顺序不同的相同请求对应同一个文件
+//
+//	t := media.NewTmdb(key, "zh", "", media.WithTmdbTransport(tmdbtest.NewRecorder("testdata/tmdb", tmdbtest.ModeRecord, nil)))
+type Recorder struct {
+	Dir  string
+	Mode int
+	Next http.RoundTripper // 录制时使用，为 nil 时使用 http.DefaultTransport
+}
+
+type recording struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+func NewRecorder(dir string, mode int, next http.RoundTripper) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode, Next: next}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	query := u.Query()
+	for _, p := range SecretParams {
+		query.Del(p)
+	}
+	u.RawQuery = query.Encode()
+	file := filepath.Join(r.Dir, recordName(req.Method, &u))
+
+	if r.Mode != ModeRecord {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, u.String())
+			}
+			return nil, err
+		}
+		rec := new(recording)
+		if err = json.Unmarshal(data, rec); err != nil {
+			return nil, err
+		}
+		return newResponse(req, rec), nil
+	}
+
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	rec := &recording{Method: req.Method, URL: u.String(), Status: resp.StatusCode, Body: string(body)}
+	// 限流和服务端错误是临时的，不保存，下次录制重新请求
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return newResponse(req, rec), nil
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(r.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		return nil, err
+	}
+	return newResponse(req, rec), nil
+}
+
+// recordName 可读的路径加上路径和查询参数的短哈希，如 search_multi_1a2b3c4d.json，
+// 不包含主机和 /3 版本前缀，真实 TMDB 和假服务录制的文件可以互相回放
+func recordName(method string, u *url.URL) string {
+	path := strings.TrimPrefix(u.Path, "/3/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	uri := path
+	if u.RawQuery != "" {
+		uri += "?" + u.RawQuery
+	}
+	sum := sha1.Sum([]byte(method + " " + uri))
+	name := strings.Trim(fileNameRe.ReplaceAllString(path, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name + "_" + hex.EncodeToString(sum[:4]) + ".json"
+}
+
+func newResponse(req *http.Request, rec *recording) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json;charset=utf-8"}},
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,82 @@
+package tmdbtest
+
+import (
+	"mediahub/internal/media"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	dir := t.TempDir()
+	record := media.NewMedia(s.NewTmdb(media.WithTmdbTransport(NewRecorder(dir, ModeRecord, nil))))
+	titles := []string{"Inception.2010.1080p.BluRay", "Breaking.Bad.S01E02.1080p.BluRay"}
+	for _, title := range titles {
+		if _, err := record.GetMediaInfo(title, ""); err != nil {
+			t.Fatalf("record %q failed, %s", title, err.Error())
+		}
+	}
+	// 服务端错误不保存
+	s.Handle("/search/multi", http.StatusInternalServerError, "")
+	if _, err := record.GetMediaInfo("流浪地球.2019.1080p", ""); err == nil {
+		t.Errorf("record with 500 succeeded")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 4 {
+		t.Errorf("recorded %d files, want 4", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), ApiKey) {
+			t.Errorf("%s contains api key", filepath.Base(file))
+		}
+	}
+	s.Close()
+
+	// 回放不需要服务，换一个地址也能回放
+	replay := media.NewMedia(media.NewTmdb("other", "zh", "", media.WithTmdbTransport(NewRecorder(dir, ModeReplay, nil))))
+	for _, title := range titles {
+		meta, err := replay.GetMediaInfo(title, "")
+		if err != nil {
+			t.Errorf("replay %q failed, %s", title, err.Error())
+			continue
+		}
+		if meta.GetMeta().TmdbId == 0 {
+			t.Errorf("replay %q not recognized", title)
+		}
+	}
+	if _, err := replay.GetMediaInfo("流浪地球.2019.1080p", ""); err == nil || !strings.Contains(err.Error(), ErrNotRecorded.Error()) {
+		t.Errorf("replay not recorded error %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestRecordName(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"/3/search/multi?query=a&language=zh", "/3/search/multi?language=zh&query=a", true},
+		{"/3/search/multi?query=a", "/3/search/multi?query=b", false},
+		{"http://localhost:1234/3/movie/1?language=zh", "https://api.themoviedb.org/3/movie/1?language=zh", true},
+		{"http://localhost:1234/movie/1?language=zh", "https://api.themoviedb.org/3/movie/1?language=zh", true},
+	}
+	name := func(raw string) string {
+		req, _ := http.NewRequest(http.MethodGet, raw, nil)
+		if !req.URL.IsAbs() {
+			req.URL.Scheme, req.URL.Host = "http", "localhost"
+		}
+		req.URL.RawQuery = req.URL.Query().Encode()
+		return recordName(req.Method, req.URL)
+	}
+	for _, tt := range tests {
+		if got := name(tt.a) == name(tt.b); got != tt.same {
+			t.Errorf("recordName(%q) == recordName(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+	if got := name("/3/search/multi?query=a"); !strings.HasPrefix(got, "search_multi_") || !strings.HasSuffix(got, ".json") {
+		t.Errorf("recordName = %q", got)
+	}
+}
@@ -0,0 +1,447 @@
+// Package tmdbtest 离线测试 TMDB 用的假服务和录制回放 transport
+//
+//	s := tmdbtest.NewServer(tmdbtest.DefaultMovies, tmdbtest.DefaultShows)
+//	defer s.Close()
+//	t := s.NewTmdb()
+//	meta, err := media.NewMedia(t).GetMediaInfo("流浪地球.2019.1080p.BluRay.x264", "")
+package tmdbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mediahub/internal/media"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ApiKey = "tmdbtest"
+
+// Movie 电影
+type Movie struct {
+	Id            int
+	Title         string
+	OriginalTitle string
+	AlsoKnownAs   []string // 其他语言的标题，参与搜索，详情中作为 alternative_titles 返回
+	ReleaseDate   string
+	Overview      string
+	ImdbId        string
+	Genres        []string
+	Language      string   // 原始语言，ISO 639-1
+	Countries     []string // 出品国家，ISO 3166-1
+	Runtime       int
+	Popularity    float32
+	VoteAverage   float32
+}
+
+// Show 电视剧
+type Show struct {
+	Id           int
+	Name         string
+	OriginalName string
+	AlsoKnownAs  []string
+	FirstAirDate string
+	Overview     string
+	ImdbId       string
+	TvdbId       int
+	Genres       []string
+	Language     string
+	Countries    []string
+	RunTime      int
+	Popularity   float32
+	VoteAverage  float32
+	Seasons      []Season
+}
+
+// Season 季，第 0 季为特别篇
+type Season struct {
+	Number   int
+	Name     string
+	AirDate  string
+	Overview string
+	Episodes []Episode
+}
+
+// Episode 集
+type Episode struct {
+	Number   int
+	Name     string
+	AirDate  string
+	Overview string
+	Runtime  int
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+// Server 假 TMDB 服务，提供 search、find、movie、tv、season、episode 接口，
+// 搜索按标题、原始标题和其他语言标题的包含关系匹配，结果按热度排序
+type Server struct {
+	*httptest.Server
+	lock      sync.Mutex
+	movies    map[int]Movie
+	shows     map[int]Show
+	overrides map[string]response
+	requests  []string
+}
+
+func NewServer(movies []Movie, shows []Show) *Server {
+	s := &Server{
+		movies:    make(map[int]Movie, len(movies)),
+		shows:     make(map[int]Show, len(shows)),
+		overrides: make(map[string]response),
+	}
+	for _, m := range movies {
+		s.movies[m.Id] = m
+	}
+	for _, show := range shows {
+		s.shows[show.Id] = show
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// NewTmdb 连接到假服务的 Tmdb
+func (s *Server) NewTmdb(opts ...media.TmdbOption) *media.Tmdb {
+	opts = append([]media.TmdbOption{media.WithTmdbBaseURL(s.URL)}, opts...)
+	return media.NewTmdb(ApiKey, "zh", "", opts...)
+}
+
+func (s *Server) AddMovie(m Movie) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.movies[m.Id] = m
+}
+
+func (s *Server) AddShow(show Show) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.shows[show.Id] = show
+}
+
+// Handle 固定 path 的响应，如 /search/multi 返回 500，用于测试失败和过期缓存。
+// 失败状态的 body 不是 TMDB 错误格式时，按 TMDB 格式返回，body 作为错误信息，为空时使用状态文本
+func (s *Server) Handle(path string, status int, body string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if status >= http.StatusMultipleChoices && !isStatusBody(body) {
+		msg := strings.TrimSpace(body)
+		if msg == "" {
+			msg = http.StatusText(status)
+		}
+		data, _ := json.Marshal(statusBody(statusCodes[status], fmt.Sprintf("%d %s", status, msg)))
+		body = string(data)
+	}
+	s.overrides[path] = response{status: status, body: body}
+}
+
+// Reset 清除 Handle 设置的响应
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.overrides = make(map[string]response)
+}
+
+// Requests 收到的请求，形如 /search/multi?query=xxx
+func (s *Server) Requests() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/3")
+	request := path
+	if q := r.URL.Query().Get("query"); q != "" {
+		request += "?query=" + q
+	}
+	s.requests = append(s.requests, request)
+
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	if r.URL.Query().Get("api_key") == "" {
+		writeStatus(w, http.StatusUnauthorized, 7, "Invalid API key: You must be granted a valid key.")
+		return
+	}
+	if resp, ok := s.overrides[path]; ok {
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+		return
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var v interface{}
+	switch {
+	case len(parts) == 2 && parts[0] == "search":
+		v = s.search(parts[1], r.URL.Query().Get("query"))
+	case len(parts) == 2 && parts[0] == "find":
+		v = s.find(parts[1], r.URL.Query().Get("external_source"))
+	case len(parts) == 2 && parts[0] == "movie":
+		v = s.movie(parts[1])
+	case len(parts) == 2 && parts[0] == "tv":
+		v = s.show(parts[1])
+	case len(parts) == 4 && parts[0] == "tv" && parts[2] == "season":
+		v = s.season(parts[1], parts[3])
+	case len(parts) == 6 && parts[0] == "tv" && parts[2] == "season" && parts[4] == "episode":
+		v = s.episode(parts[1], parts[3], parts[5])
+	}
+	if v == nil {
+		writeStatus(w, http.StatusNotFound, 34, "The resource you requested could not be found.")
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeStatus(w http.ResponseWriter, status int, code int, msg string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(statusBody(code, msg))
+}
+
+// statusCodes HTTP 状态对应的 TMDB status_code
+var statusCodes = map[int]int{
+	http.StatusUnauthorized:        7,
+	http.StatusNotFound:            34,
+	http.StatusTooManyRequests:     25,
+	http.StatusInternalServerError: 11,
+	http.StatusServiceUnavailable:  9,
+}
+
+func statusBody(code int, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"status_code":    code,
+		"status_message": msg,
+		"success":        false,
+	}
+}
+
+func isStatusBody(body string) bool {
+	var v struct {
+		StatusMessage string `json:"status_message"`
+	}
+	return json.Unmarshal([]byte(body), &v) == nil && v.StatusMessage != ""
+}
+
+// search kind 为 multi、movie、tv
+func (s *Server) search(kind string, query string) interface{} {
+	q := strings.ToLower(strings.TrimSpace(query))
+	results := make([]map[string]interface{}, 0)
+	match := func(names ...string) bool {
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if q != "" && name != "" && (strings.Contains(name, q) || strings.Contains(q, name)) {
+				return true
+			}
+		}
+		return false
+	}
+	if kind == "multi" || kind == "movie" {
+		for _, m := range s.movies {
+			if match(append([]string{m.Title, m.OriginalTitle}, m.AlsoKnownAs...)...) {
+				results = append(results, map[string]interface{}{
+					"id": m.Id, "media_type": "movie", "title": m.Title, "original_title": m.OriginalTitle,
+					"release_date": m.ReleaseDate, "overview": m.Overview, "popularity": m.Popularity,
+					"vote_average": m.VoteAverage,
+				})
+			}
+		}
+	}
+	if kind == "multi" || kind == "tv" {
+		for _, show := range s.shows {
+			if match(append([]string{show.Name, show.OriginalName}, show.AlsoKnownAs...)...) {
+				results = append(results, map[string]interface{}{
+					"id": show.Id, "media_type": "tv", "name": show.Name, "original_name": show.OriginalName,
+					"first_air_date": show.FirstAirDate, "overview": show.Overview, "popularity": show.Popularity,
+					"vote_average": show.VoteAverage,
+				})
+			}
+		}
+	}
+	sortByPopularity(results)
+	return map[string]interface{}{
+		"page":          1,
+		"results":       results,
+		"total_pages":   1,
+		"total_results": len(results),
+	}
+}
+
+// find 按 imdb_id、tvdb_id 查找
+func (s *Server) find(id string, source string) interface{} {
+	movies := make([]map[string]interface{}, 0)
+	shows := make([]map[string]interface{}, 0)
+	for _, m := range s.movies {
+		if source == "imdb_id" && m.ImdbId != "" && m.ImdbId == id {
+			movies = append(movies, map[string]interface{}{
+				"id": m.Id, "title": m.Title, "original_title": m.OriginalTitle,
+				"release_date": m.ReleaseDate, "popularity": m.Popularity,
+			})
+		}
+	}
+	for _, show := range s.shows {
+		if (source == "imdb_id" && show.ImdbId != "" && show.ImdbId == id) ||
+			(source == "tvdb_id" && show.TvdbId != 0 && strconv.Itoa(show.TvdbId) == id) {
+			shows = append(shows, map[string]interface{}{
+				"id": show.Id, "name": show.Name, "original_name": show.OriginalName,
+				"first_air_date": show.FirstAirDate, "popularity": show.Popularity,
+			})
+		}
+	}
+	return map[string]interface{}{
+		"movie_results":      movies,
+		"tv_results":         shows,
+		"person_results":     []interface{}{},
+		"tv_episode_results": []interface{}{},
+		"tv_season_results":  []interface{}{},
+	}
+}
+
+func sortByPopularity(results []map[string]interface{}) {
+	sort.Slice(results, func(i, j int) bool {
+		pi, pj := results[i]["popularity"].(float32), results[j]["popularity"].(float32)
+		if pi != pj {
+			return pi > pj
+		}
+		return results[i]["id"].(int) < results[j]["id"].(int)
+	})
+}
+
+// GenreIds TMDB 中文类型名称对应的 ID，未列出的类型按顺序编号
+var GenreIds = map[string]int{
+	"动作": 28, "冒险": 12, "动画": 16, "喜剧": 35, "犯罪": 80, "纪录": 99, "剧情": 18, "家庭": 10751,
+	"奇幻": 14, "历史": 36, "恐怖": 27, "音乐": 10402, "悬疑": 9648, "爱情": 10749, "科幻": 878,
+	"惊悚": 53, "战争": 10752, "西部": 37, "动作冒险": 10759, "儿童": 10762, "真人秀": 10764,
+	"Sci-Fi & Fantasy": 10765, "脱口秀": 10767,
+}
+
+func genres(names []string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(names))
+	for i, name := range names {
+		id, ok := GenreIds[name]
+		if !ok {
+			id = i + 1
+		}
+		result = append(result, map[string]interface{}{"id": id, "name": name})
+	}
+	return result
+}
+
+func countries(codes []string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, map[string]interface{}{"iso_3166_1": code, "name": code})
+	}
+	return result
+}
+
+func alternativeTitles(titles []string) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(titles))
+	for _, title := range titles {
+		result = append(result, map[string]interface{}{"iso_3166_1": "US", "title": title, "type": ""})
+	}
+	return result
+}
+
+func (s *Server) movie(id string) interface{} {
+	n, _ := strconv.Atoi(id)
+	m, ok := s.movies[n]
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		"id": m.Id, "title": m.Title, "original_title": m.OriginalTitle, "release_date": m.ReleaseDate,
+		"overview": m.Overview, "imdb_id": m.ImdbId, "genres": genres(m.Genres), "runtime": m.Runtime,
+		"original_language": m.Language, "production_countries": countries(m.Countries),
+		"popularity": m.Popularity, "vote_average": m.VoteAverage,
+		"external_ids":       map[string]interface{}{"imdb_id": m.ImdbId},
+		"alternative_titles": map[string]interface{}{"titles": alternativeTitles(m.AlsoKnownAs)},
+	}
+}
+
+func (s *Server) show(id string) interface{} {
+	n, _ := strconv.Atoi(id)
+	show, ok := s.shows[n]
+	if !ok {
+		return nil
+	}
+	seasons := make([]map[string]interface{}, 0, len(show.Seasons))
+	for _, season := range show.Seasons {
+		seasons = append(seasons, map[string]interface{}{
+			"id": show.Id*100 + season.Number, "season_number": season.Number, "name": season.Name,
+			"air_date": season.AirDate, "overview": season.Overview, "episode_count": len(season.Episodes),
+		})
+	}
+	runtime := []int{}
+	if show.RunTime != 0 {
+		runtime = append(runtime, show.RunTime)
+	}
+	return map[string]interface{}{
+		"id": show.Id, "name": show.Name, "original_name": show.OriginalName, "first_air_date": show.FirstAirDate,
+		"overview": show.Overview, "genres": genres(show.Genres), "episode_run_time": runtime,
+		"original_language": show.Language, "origin_country": show.Countries,
+		"production_countries": countries(show.Countries),
+		"number_of_seasons":    len(show.Seasons), "seasons": seasons,
+		"popularity": show.Popularity, "vote_average": show.VoteAverage,
+		"external_ids":       map[string]interface{}{"imdb_id": show.ImdbId, "tvdb_id": show.TvdbId},
+		"alternative_titles": map[string]interface{}{"results": alternativeTitles(show.AlsoKnownAs)},
+	}
+}
+
+func (s *Server) findSeason(id string, number string) (Show, *Season) {
+	n, _ := strconv.Atoi(id)
+	show, ok := s.shows[n]
+	if !ok {
+		return show, nil
+	}
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return show, nil
+	}
+	for i := range show.Seasons {
+		if show.Seasons[i].Number == num {
+			return show, &show.Seasons[i]
+		}
+	}
+	return show, nil
+}
+
+func episodeJson(show Show, season *Season, e Episode) map[string]interface{} {
+	return map[string]interface{}{
+		"id": show.Id*10000 + season.Number*100 + e.Number, "show_id": show.Id,
+		"season_number": season.Number, "episode_number": e.Number, "name": e.Name,
+		"air_date": e.AirDate, "overview": e.Overview, "runtime": e.Runtime,
+	}
+}
+
+func (s *Server) season(id string, number string) interface{} {
+	show, season := s.findSeason(id, number)
+	if season == nil {
+		return nil
+	}
+	episodes := make([]map[string]interface{}, 0, len(season.Episodes))
+	for _, e := range season.Episodes {
+		episodes = append(episodes, episodeJson(show, season, e))
+	}
+	return map[string]interface{}{
+		"id": show.Id*100 + season.Number, "season_number": season.Number, "name": season.Name,
+		"air_date": season.AirDate, "overview": season.Overview, "episodes": episodes,
+	}
+}
+
+func (s *Server) episode(id string, number string, episode string) interface{} {
+	show, season := s.findSeason(id, number)
+	if season == nil {
+		return nil
+	}
+	num, _ := strconv.Atoi(episode)
+	for _, e := range season.Episodes {
+		if e.Number == num {
+			return episodeJson(show, season, e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package tmdbtest
+
+import (
+	"errors"
+	"mediahub/internal/media"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetMediaInfo(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	m := media.NewMedia(s.NewTmdb())
+
+	tests := []struct {
+		title     string
+		mediaType int
+		tmdbId    int
+		name      string
+		year      int
+		season    int
+		episode   int
+		category  string
+	}{
+		{"流浪地球.2019.1080p.BluRay.x264", media.MediaTypeMovie, 535167, "流浪地球", 2019, 0, 0, "华语电影"},
+		// 英文名通过其他标题匹配
+		{"The.Wandering.Earth.2019.1080p", media.MediaTypeMovie, 535167, "流浪地球", 2019, 0, 0, "华语电影"},
+		{"The.Wandering.Earth.II.2023.2160p.WEB-DL", media.MediaTypeMovie, 842675, "流浪地球2", 2023, 0, 0, "华语电影"},
+		{"流浪地球2.2023.4K", media.MediaTypeMovie, 842675, "流浪地球2", 2023, 0, 0, "华语电影"},
+		{"Inception.2010.1080p.BluRay", media.MediaTypeMovie, 27205, "盗梦空间", 2010, 0, 0, "外语电影"},
+		{"Breaking.Bad.S01E02.1080p.BluRay", media.MediaTypeTv, 1396, "绝命毒师", 2008, 1, 2, "欧美剧"},
+		{"绝命毒师.S02E01.720p", media.MediaTypeTv, 1396, "绝命毒师", 2008, 2, 1, "欧美剧"},
+		{"Breaking.Bad.S00E01.720p", media.MediaTypeTv, 1396, "绝命毒师", 2008, 0, 1, "欧美剧"},
+		{"The.Knockout.S01E03.2160p", media.MediaTypeTv, 215803, "狂飙", 2023, 1, 3, "国产剧"},
+		{"[Nekomoe kissaten][Sousou no Frieren][01][1080p][JPSC].mp4", media.MediaTypeTv, 209867, "葬送的芙莉莲", 2023, 0, 1, "日番"},
+		{"[ANi] Frieren - 02 [1080P][CHS].mp4", media.MediaTypeTv, 209867, "葬送的芙莉莲", 2023, 0, 2, "日番"},
+	}
+	for _, tt := range tests {
+		meta, err := m.GetMediaInfo(tt.title, "")
+		if err != nil {
+			t.Errorf("GetMediaInfo(%q) failed, %s", tt.title, err.Error())
+			continue
+		}
+		info := meta.GetMeta()
+		if info.MediaType != tt.mediaType || info.TmdbId != tt.tmdbId || info.Title != tt.name || info.Year != tt.year ||
+			info.BeginSeason != tt.season || info.BeginEpisode != tt.episode || info.Category != tt.category {
+			t.Errorf("GetMediaInfo(%q) = type %d id %d %q %d S%dE%d %q", tt.title, info.MediaType, info.TmdbId,
+				info.Title, info.Year, info.BeginSeason, info.BeginEpisode, info.Category)
+		}
+	}
+
+	if _, err := m.GetMediaInfo("Nothing.Here.2019.1080p", ""); !errors.Is(err, media.ErrMediaNotFound) {
+		t.Errorf("GetMediaInfo of unknown title error %v, want %v", err, media.ErrMediaNotFound)
+	}
+}
+
+func TestGetMediaInfoById(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	m := media.NewMedia(s.NewTmdb())
+
+	tests := []struct {
+		title  string
+		tmdbId int
+		imdbId string
+		tvdbId int
+	}{
+		{"Movie {tmdb-1396} S01E01", 1396, "tt0903747", 81189},
+		{"Show [imdbid-tt0903747] S01E02", 1396, "tt0903747", 81189},
+		{"x {tvdb-424536} - 01", 209867, "tt22248376", 424536},
+		{"Unknown.Title.2019.1080p [imdbid-tt1375666]", 27205, "tt1375666", 0},
+	}
+	for _, tt := range tests {
+		meta, err := m.GetMediaInfo(tt.title, "")
+		if err != nil {
+			t.Errorf("GetMediaInfo(%q) failed, %s", tt.title, err.Error())
+			continue
+		}
+		info := meta.GetMeta()
+		if info.TmdbId != tt.tmdbId || info.ImdbId != tt.imdbId || info.TvdbId != tt.tvdbId {
+			t.Errorf("GetMediaInfo(%q) ids = %d %q %d, want %d %q %d", tt.title,
+				info.TmdbId, info.ImdbId, info.TvdbId, tt.tmdbId, tt.imdbId, tt.tvdbId)
+		}
+	}
+
+	tm := s.NewTmdb()
+	results, err := tm.FindByTvdbId(81189)
+	if err != nil || len(results) != 1 || results[0].Id != "1396" || results[0].MediaType != media.MediaTypeTv {
+		t.Errorf("FindByTvdbId(81189) = %v, %v", results, err)
+	}
+	results, err = tm.FindByImdbId("tt0000000")
+	if err != nil || len(results) != 0 {
+		t.Errorf("FindByImdbId(tt0000000) = %v, %v", results, err)
+	}
+}
+
+func TestSeasonEpisodes(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	tm := s.NewTmdb()
+	m := media.NewMedia(tm)
+
+	seasons, err := tm.GetSeasons(1396)
+	if err != nil {
+		t.Fatalf("GetSeasons failed, %s", err.Error())
+	}
+	if len(seasons) != 3 || !seasons[0].IsSpecial() || seasons[1].EpisodeCount != 3 {
+		t.Errorf("GetSeasons = %d seasons", len(seasons))
+	}
+	episode, err := tm.GetEpisodeDetail(1396, 1, 1)
+	if err != nil || episode.Name != "试播集" || episode.Runtime != 58 {
+		t.Errorf("GetEpisodeDetail = %+v, %v", episode, err)
+	}
+	if _, err = tm.GetSeasonDetail(1396, 9); err == nil {
+		t.Errorf("GetSeasonDetail of missing season succeeded")
+	}
+
+	tests := []struct {
+		title    string
+		episodes []string
+	}{
+		{"Breaking.Bad.S01E02.1080p.BluRay", []string{"猫在袋子里"}},
+		{"Breaking.Bad.S01E02-E03.1080p.BluRay", []string{"猫在袋子里", "袋子在河里"}},
+		{"Breaking.Bad.S01.1080p.BluRay", []string{"试播集", "猫在袋子里", "袋子在河里"}},
+		{"Breaking.Bad.S00E01.720p", []string{"Good Cop Bad Cop"}},
+		{"狂飙.2023.01.15.1080p", []string{"第 3 集"}},
+	}
+	for _, tt := range tests {
+		meta, err := m.GetMediaInfo(tt.title, "")
+		if err != nil {
+			t.Errorf("GetMediaInfo(%q) failed, %s", tt.title, err.Error())
+			continue
+		}
+		episodes, err := m.GetEpisodes(meta)
+		if err != nil {
+			t.Errorf("GetEpisodes(%q) failed, %s", tt.title, err.Error())
+			continue
+		}
+		names := make([]string, 0, len(episodes))
+		for _, e := range episodes {
+			names = append(names, e.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.episodes, ",") {
+			t.Errorf("GetEpisodes(%q) = %q, want %q", tt.title, names, tt.episodes)
+		}
+	}
+}
+
+func TestServerError(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	tm := s.NewTmdb()
+
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{http.StatusInternalServerError, "", "code: 11 | success: false | message: 500 Internal Server Error"},
+		{http.StatusServiceUnavailable, "maintenance", "code: 9 | success: false | message: 503 maintenance"},
+		{http.StatusUnauthorized, `{"status_code":7,"status_message":"Invalid API key","success":false}`,
+			"code: 7 | success: false | message: Invalid API key"},
+	}
+	for _, tt := range tests {
+		s.Handle("/search/multi", tt.status, tt.body)
+		_, err := tm.QueryByName(tt.body)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Handle(%d, %q) error %v, want %s", tt.status, tt.body, err, tt.want)
+		}
+	}
+
+	// 请求失败不能当作没有结果
+	s.Handle("/search/multi", http.StatusInternalServerError, "")
+	_, err := media.NewMedia(tm).GetMediaInfo("流浪地球.2019.1080p", "")
+	if err == nil || errors.Is(err, media.ErrMediaNotFound) || !strings.Contains(err.Error(), "500") {
+		t.Errorf("GetMediaInfo after 500 error %v", err)
+	}
+	s.Reset()
+	if _, err = media.NewMedia(tm).GetMediaInfo("流浪地球.2019.1080p", ""); err != nil {
+		t.Errorf("GetMediaInfo after Reset failed, %s", err.Error())
+	}
+}
+
+// gateTransport 请求阻塞到 release 关闭
+type gateTransport struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (g *gateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCoalesce(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	gate := &gateTransport{started: make(chan struct{}), release: make(chan struct{})}
+	tm := s.NewTmdb(media.WithTmdbTransport(gate))
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := tm.QueryByName("流浪地球")
+			errs <- err
+		}()
+	}
+	<-gate.started
+	time.Sleep(50 * time.Millisecond)
+	close(gate.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("QueryByName failed, %s", err.Error())
+		}
+	}
+	if got := len(s.Requests()); got != 1 {
+		t.Errorf("%d concurrent queries sent %d requests, want 1", n, got)
+	}
+	if stats := tm.Stats(); stats.Misses != 1 || stats.Shared+stats.Hits != n-1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestStaleFallback(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	tm := s.NewTmdb()
+	tm.SetTTL(time.Nanosecond, time.Nanosecond)
+
+	if _, err := tm.QueryByName("流浪地球"); err != nil {
+		t.Fatalf("QueryByName failed, %s", err.Error())
+	}
+	s.Handle("/search/multi", http.StatusInternalServerError, "")
+	search, err := tm.QueryByName("流浪地球")
+	if err != nil || len(search.Results) != 2 {
+		t.Fatalf("QueryByName with stale cache = %v, %v", search, err)
+	}
+	if stats := tm.Stats(); stats.Stale != 1 || stats.Errors != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+	if _, err = tm.QueryByName("狂飙"); err == nil {
+		t.Errorf("QueryByName without cache succeeded")
+	}
+}
+
+func TestOffline(t *testing.T) {
+	s := NewServer(DefaultMovies, DefaultShows)
+	defer s.Close()
+	tm := s.NewTmdb()
+	m := media.NewMedia(tm)
+
+	if _, err := m.GetMediaInfo("Inception.2010.1080p.BluRay", ""); err != nil {
+		t.Fatalf("GetMediaInfo failed, %s", err.Error())
+	}
+	requests := len(s.Requests())
+	tm.SetOffline(true)
+	meta, err := m.GetMediaInfo("Inception.2010.1080p.BluRay", "")
+	if err != nil || meta.GetMeta().TmdbId != 27205 {
+		t.Errorf("GetMediaInfo offline = %v, %v", meta, err)
+	}
+	if _, err = tm.QueryByName("狂飙"); !errors.Is(err, media.ErrTmdbOffline) {
+		t.Errorf("QueryByName offline without cache error %v, want %v", err, media.ErrTmdbOffline)
+	}
+	if _, err = m.GetMediaInfo("狂飙.第01集.4K", ""); !errors.Is(err, media.ErrTmdbOffline) {
+		t.Errorf("GetMediaInfo offline without cache error %v, want %v", err, media.ErrTmdbOffline)
+	}
+	if got := len(s.Requests()); got != requests {
+		t.Errorf("offline sent %d requests", got-requests)
+	}
+}
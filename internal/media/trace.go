@@ -0,0 +1,171 @@
+package media
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var parseStateNames = map[int]string{
+	ParseStatePart:        "Part",
+	ParseStateCN:          "CN",
+	ParseStateEN:          "EN",
+	ParseSeasonEpisode:    "SeasonEpisode",
+	ParseStateYear:        "Year",
+	ParseStatePix:         "Pix",
+	ParseStateSeason:      "Season",
+	ParseStateEpisode:     "Episode",
+	ParseStateSource:      "Source",
+	ParseStateEffect:      "Effect",
+	ParseStateVideoEncode: "VideoEncode",
+	ParseStateAudioEncode: "AudioEncode",
+	ParseStateJP:          "JP",
+	ParseStateKR:          "KR",
+	ParseStateAirDate:     "AirDate",
+}
+
+func ParseStateName(state int) string {
+	if name, ok := parseStateNames[state]; ok {
+		return name
+	}
+	return "-"
+}
+
+// FieldChange Meta 字段变化
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TraceStep 一个 Step 处理一个 token 的记录
+type TraceStep struct {
+	Step      string        `json:"step"`
+	Stop      bool          `json:"stop"` // 是否终止后续 Step
+	StateFrom string        `json:"state_from"`
+	StateTo   string        `json:"state_to"`
+	FlagFrom  int           `json:"flag_from"`
+	FlagTo    int           `json:"flag_to"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+}
+
+// TraceToken 一个 token 的处理记录
+type TraceToken struct {
+	Index int          `json:"index"`
+	Token string       `json:"token"`
+	Steps []*TraceStep `json:"steps"`
+}
+
+// Trace 标题解析过程
+type Trace struct {
+	Parser  string        `json:"parser"`  // 使用的解析器 video、anim
+	Input   string        `json:"input"`   // 原始标题
+	Revised string        `json:"revised"` // 识别词处理后的标题
+	Title   string        `json:"title"`   // 预处理后进入分词的标题
+	Tokens  []*TraceToken `json:"tokens,omitempty"`
+	Finish  []FieldChange `json:"finish,omitempty"` // 分词解析后的收尾处理
+}
+
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+func (t *Trace) beginToken(index int, token string) *TraceToken {
+	tt := &TraceToken{Index: index, Token: token}
+	t.Tokens = append(t.Tokens, tt)
+	return tt
+}
+
+// runStep 执行 Step 并记录状态变化
+func (t *Trace) runStep(tt *TraceToken, p *Parser, s Step, token string, meta *Meta) bool {
+	ts := &TraceStep{
+		Step:      stepName(s),
+		StateFrom: ParseStateName(p.tokenState),
+		FlagFrom:  p.flag,
+	}
+	before := metaFields(meta)
+	next := s.Run(p, token)
+	ts.Stop = !next
+	ts.StateTo = ParseStateName(p.tokenState)
+	ts.FlagTo = p.flag
+	ts.Changes = diffFields(before, metaFields(meta))
+	tt.Steps = append(tt.Steps, ts)
+	return next
+}
+
+func (t *Trace) String() string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "parser:  %s\n", t.Parser)
+	fmt.Fprintf(&b, "input:   %s\n", t.Input)
+	if t.Revised != t.Input {
+		fmt.Fprintf(&b, "revised: %s\n", t.Revised)
+	}
+	if t.Title != "" {
+		fmt.Fprintf(&b, "title:   %s\n", t.Title)
+	}
+	for _, tt := range t.Tokens {
+		fmt.Fprintf(&b, "token[%d] %q\n", tt.Index, tt.Token)
+		for _, ts := range tt.Steps {
+			result := "continue"
+			if ts.Stop {
+				result = "stop"
+			}
+			fmt.Fprintf(&b, "  %-18s %-8s state %s -> %s  flag %d -> %d\n",
+				ts.Step, result, ts.StateFrom, ts.StateTo, ts.FlagFrom, ts.FlagTo)
+			writeChanges(&b, ts.Changes)
+		}
+	}
+	if len(t.Finish) > 0 {
+		b.WriteString("finish\n")
+		writeChanges(&b, t.Finish)
+	}
+	return b.String()
+}
+
+func writeChanges(b *strings.Builder, changes []FieldChange) {
+	for _, c := range changes {
+		fmt.Fprintf(b, "      %s: %q -> %q\n", c.Field, c.Old, c.New)
+	}
+}
+
+func stepName(s Step) string {
+	if n, ok := s.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	t := reflect.TypeOf(s)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// metaFields 导出字段的字符串快照
+func metaFields(m *Meta) map[string]string {
+	v := reflect.ValueOf(m).Elem()
+	t := v.Type()
+	fields := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		fields[t.Field(i).Name] = fmt.Sprint(v.Field(i).Interface())
+	}
+	return fields
+}
+
+func diffFields(before, after map[string]string) []FieldChange {
+	var changes []FieldChange
+	for field, val := range after {
+		if old := before[field]; old != val {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: val})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
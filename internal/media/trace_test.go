@@ -0,0 +1,113 @@
+package media
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stopStep token 上终止后续 Step 的 Step 和它修改的字段
+func stopStep(tt *TraceToken) (string, []string) {
+	for _, ts := range tt.Steps {
+		if ts.Stop {
+			fields := make([]string, 0, len(ts.Changes))
+			for _, c := range ts.Changes {
+				fields = append(fields, c.Field)
+			}
+			return ts.Step, fields
+		}
+	}
+	return "", nil
+}
+
+func TestExplainMeta(t *testing.T) {
+	title := "Inception.2010.1080p.BluRay.x264-CMCT"
+	meta, trace := ExplainMeta(title, "", MediaUnknown, false)
+	if meta == nil || trace == nil {
+		t.Fatalf("ExplainMeta(%q) = nil", title)
+	}
+	// 跟踪不影响识别结果
+	got, want := *meta.GetMeta(), *NewMeta(title, "", MediaUnknown, false).GetMeta()
+	got.trace = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExplainMeta meta %+v, want %+v", got, want)
+	}
+	if trace.Parser != "video" || trace.Input != title || trace.Title != "Inception.2010.1080p.BluRay.x264" {
+		t.Errorf("trace parser %q input %q title %q", trace.Parser, trace.Input, trace.Title)
+	}
+
+	tests := []struct {
+		token  string
+		step   string
+		fields []string
+	}{
+		{"Inception", "", nil},
+		{"2010", "ParseYear", []string{"Year"}},
+		{"1080p", "ParseResourcePix", []string{"ResourcePix"}},
+		{"BluRay", "ParseResourceType", []string{}},
+		{"x264", "ParseVideoEncode", []string{"VideoEncode"}},
+	}
+	if len(trace.Tokens) != len(tests) {
+		t.Fatalf("trace has %d tokens, want %d", len(trace.Tokens), len(tests))
+	}
+	for i, tt := range tests {
+		token := trace.Tokens[i]
+		if token.Index != i || token.Token != tt.token {
+			t.Errorf("token[%d] = %d %q, want %q", i, token.Index, token.Token, tt.token)
+			continue
+		}
+		step, fields := stopStep(token)
+		if step != tt.step || len(fields) != len(tt.fields) || len(fields) > 0 && !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("token %q stopped at %q changing %q, want %q changing %q", tt.token, step, fields, tt.step, tt.fields)
+		}
+	}
+	name := trace.Tokens[0].Steps[4]
+	if name.Step != "ParseName" || name.StateTo != "EN" || len(name.Changes) != 1 ||
+		name.Changes[0] != (FieldChange{Field: "EnName", Old: "", New: "Inception"}) {
+		t.Errorf("ParseName on Inception = %+v", name)
+	}
+	if !containsChange(trace.Finish, "MediaType") || !containsChange(trace.Finish, "ResourceType") {
+		t.Errorf("finish changes %+v", trace.Finish)
+	}
+
+	s := trace.String()
+	for _, want := range []string{"parser:  video", `token[1] "2010"`, "ParseYear", `Year: "0" -> "2010"`, "finish"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("trace string missing %q:\n%s", want, s)
+		}
+	}
+}
+
+func containsChange(changes []FieldChange, field string) bool {
+	for _, c := range changes {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExplainMetaAnim(t *testing.T) {
+	_, trace := ExplainMeta("[ANi] Frieren - 05 [1080P]", "", MediaUnknown, false)
+	if trace == nil || trace.Parser != "anim" || len(trace.Tokens) != 0 {
+		t.Fatalf("anim trace = %+v", trace)
+	}
+	for _, field := range []string{"EnName", "BeginEpisode", "ReleaseGroup", "ResourcePix"} {
+		if !containsChange(trace.Finish, field) {
+			t.Errorf("anim finish missing %s: %+v", field, trace.Finish)
+		}
+	}
+}
+
+func TestTraceNil(t *testing.T) {
+	if meta, trace := ExplainMeta("", "", MediaUnknown, false); meta != nil || trace != nil {
+		t.Errorf("ExplainMeta of empty title = %v, %v", meta, trace)
+	}
+	var trace *Trace
+	if s := trace.String(); s != "" {
+		t.Errorf("nil Trace.String() = %q", s)
+	}
+	if name := ParseStateName(-1); name != "-" {
+		t.Errorf("ParseStateName(-1) = %q", name)
+	}
+}
@@ -0,0 +1,266 @@
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/hashicorp/golang-lru/arc/v2"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	TvdbApiURL = "https://api4.thetvdb.com/v4"
+)
+
+// TvdbLanguages TMDB 语言代码对应的 TVDB 语言代码
+var TvdbLanguages = map[string]string{
+	"zh": "zho",
+	"en": "eng",
+	"ja": "jpn",
+	"ko": "kor",
+}
+
+// Tvdb TheTVDB v4，部分剧集的季集顺序以 TVDB 为准
+type Tvdb struct {
+	client      http.Client
+	apiKey      string
+	pin         string
+	language    string
+	token       string
+	lock        sync.Mutex
+	searchCache *arc.ARCCache[string, []*SearchResult]
+	detailCache *arc.ARCCache[string, *MediaDetail]
+}
+
+type tvdbRemoteId struct {
+	Id         string `json:"id"`
+	SourceName string `json:"sourceName"`
+}
+
+type tvdbSearchResult struct {
+	TvdbId       string            `json:"tvdb_id"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	Year         string            `json:"year"`
+	Translations map[string]string `json:"translations"`
+}
+
+type tvdbRecord struct {
+	Id             int            `json:"id"`
+	Name           string         `json:"name"`
+	Year           string         `json:"year"`
+	FirstAired     string         `json:"firstAired"`
+	Overview       string         `json:"overview"`
+	Runtime        int            `json:"runtime"`
+	AverageRuntime int            `json:"averageRuntime"`
+	RemoteIds      []tvdbRemoteId `json:"remoteIds"`
+	Genres         []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	FirstRelease struct {
+		Date string `json:"date"`
+	} `json:"first_release"`
+}
+
+type tvdbTranslation struct {
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+}
+
+func NewTvdb(apiKey string, pin string, language string, proxyUrl string) *Tvdb {
+	searchCache, err := arc.NewARC[string, []*SearchResult](512)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	detailCache, err := arc.NewARC[string, *MediaDetail](512)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if lang, ok := TvdbLanguages[language]; ok {
+		language = lang
+	} else {
+		language = "eng"
+	}
+	return &Tvdb{
+		client:      newHttpClient(proxyUrl),
+		apiKey:      apiKey,
+		pin:         pin,
+		language:    language,
+		searchCache: searchCache,
+		detailCache: detailCache,
+	}
+}
+
+func (t *Tvdb) Name() string {
+	return ProviderTvdb
+}
+
+// login 获取 token，有效期一个月，失效后重新登录
+func (t *Tvdb) login() (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.token != "" {
+		return t.token, nil
+	}
+	body, err := json.Marshal(map[string]string{"apikey": t.apiKey, "pin": t.pin})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, TvdbApiURL+"/login", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err = getJson(&t.client, req, &resp); err != nil {
+		return "", err
+	}
+	t.token = resp.Data.Token
+	return t.token, nil
+}
+
+// get 请求接口，解析 data 字段，token 失效时重新登录一次
+func (t *Tvdb) get(path string, v interface{}) error {
+	for retry := 0; ; retry++ {
+		token, err := t.login()
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest(http.MethodGet, TvdbApiURL+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := struct {
+			Data interface{} `json:"data"`
+		}{Data: v}
+		err = getJson(&t.client, req, &resp)
+		var statusErr *statusError
+		if retry == 0 && errors.As(err, &statusErr) && statusErr.Code == http.StatusUnauthorized {
+			t.lock.Lock()
+			t.token = ""
+			t.lock.Unlock()
+			continue
+		}
+		return err
+	}
+}
+
+// SearchMedia 实现 Provider，有当前语言的译名时作为标题
+func (t *Tvdb) SearchMedia(name string) ([]*SearchResult, error) {
+	if results, ok := t.searchCache.Get(name); ok {
+		return results, nil
+	}
+	var records []tvdbSearchResult
+	if err := t.get("/search?query="+url.QueryEscape(name), &records); err != nil {
+		return nil, err
+	}
+	results := make([]*SearchResult, 0, len(records))
+	for i, r := range records {
+		result := &SearchResult{
+			Provider:      ProviderTvdb,
+			Id:            r.TvdbId,
+			Title:         r.Name,
+			OriginalTitle: r.Name,
+			Popularity:    float32(len(records) - i),
+		}
+		switch r.Type {
+		case "movie":
+			result.MediaType = MediaTypeMovie
+		case "series":
+			result.MediaType = MediaTypeTv
+		default:
+			continue
+		}
+		if title := r.Translations[t.language]; title != "" {
+			result.Title = title
+		}
+		result.Year, _ = strconv.Atoi(r.Year)
+		results = append(results, result)
+	}
+	t.searchCache.Add(name, results)
+	return results, nil
+}
+
+// GetMovieInfo 实现 Provider
+func (t *Tvdb) GetMovieInfo(id string) (*MediaDetail, error) {
+	return t.getRecord("movies", id)
+}
+
+// GetTvInfo 实现 Provider
+func (t *Tvdb) GetTvInfo(id string) (*MediaDetail, error) {
+	return t.getRecord("series", id)
+}
+
+// GetExternalIds 实现 Provider
+func (t *Tvdb) GetExternalIds(id string, mediaType int) (*ExternalIds, error) {
+	detail, err := getDetail(t, id, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	return &detail.Ids, nil
+}
+
+func (t *Tvdb) getRecord(kind string, id string) (*MediaDetail, error) {
+	key := kind + ":" + id
+	if detail, ok := t.detailCache.Get(key); ok {
+		return detail, nil
+	}
+	record := new(tvdbRecord)
+	if err := t.get("/"+kind+"/"+url.PathEscape(id)+"/extended?short=true", record); err != nil {
+		return nil, err
+	}
+	detail := &MediaDetail{
+		Provider:      ProviderTvdb,
+		Id:            id,
+		MediaType:     MediaTypeMovie,
+		Title:         record.Name,
+		OriginalTitle: record.Name,
+		ReleaseDate:   record.FirstRelease.Date,
+		Genres:        make([]string, 0, len(record.Genres)),
+		Overview:      record.Overview,
+		Runtime:       record.Runtime,
+		Ids:           ExternalIds{TvdbId: record.Id},
+	}
+	if kind == "series" {
+		detail.MediaType = MediaTypeTv
+		detail.ReleaseDate = record.FirstAired
+		detail.Runtime = record.AverageRuntime
+	}
+	detail.Year, _ = strconv.Atoi(record.Year)
+	for _, g := range record.Genres {
+		detail.Genres = append(detail.Genres, g.Name)
+	}
+	// remoteIds 的 sourceName 为 IMDB、TheMovieDB.com 等
+	for _, r := range record.RemoteIds {
+		source := strings.ToLower(r.SourceName)
+		switch {
+		case strings.Contains(source, "imdb"):
+			detail.Ids.ImdbId = r.Id
+		case strings.Contains(source, "themoviedb"):
+			detail.Ids.TmdbId, _ = strconv.Atoi(r.Id)
+		}
+	}
+	if t.language != "eng" {
+		translation := new(tvdbTranslation)
+		if err := t.get("/"+kind+"/"+url.PathEscape(id)+"/translations/"+t.language, translation); err == nil {
+			if translation.Name != "" {
+				detail.Title = translation.Name
+			}
+			if translation.Overview != "" {
+				detail.Overview = translation.Overview
+			}
+		}
+	}
+	t.detailCache.Add(key, detail)
+	return detail, nil
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// TmdbCache TMDB 搜索结果和详情缓存
+type TmdbCache struct {
+	Key       string    `json:"key" gorm:"primaryKey;column:cache_key"` // movie:123:zh、tv:123:zh、search:名称:zh
+	Data      []byte    `json:"-"`                                      // json
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`                // 过期时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// CustomWord 自定义识别词
+type CustomWord struct {
+	ID       uint   `json:"id" gorm:"primaryKey"` // unique key
+	Word     string `json:"word" gorm:"not null"` // 识别词，A、A => B、前 <> 后 >> EP+1
+	Regex    bool   `json:"regex"`                // 是否正则
+	Disabled bool   `json:"disabled"`             // 是否停用
+	Note     string `json:"note"`                 // 备注
+}
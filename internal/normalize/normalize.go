@@ -0,0 +1,273 @@
+package normalize
+
+import (
+	"mediahub/internal/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 常用繁体字与简体字对照，按位置一一对应。只收录一对一的字，本身也是规范简体字的（著、乾）不转换，
+// 多个繁体字对应同一简体字的（臺、檯、颱）只保留常用的一个
+var (
+	traditional = "" +
+		"萬與醜專業叢東絲兩嚴喪個豐臨為麗舉麼義烏樂喬習鄉書買亂爭虧雲亞產畝親億僅從侖倉儀們價眾優會傘偉傳傷倫偽體餘傭僉俠侶僥" +
+		"偵側僑儈儕儂俁儔儼倆儷儉債傾僂僨償儻儐儲儺兒兌黨蘭關興養獸內岡冊寫軍農馮沖決況凍淨涼減湊凜幾鳳憑凱擊鑿芻劃劉則剛創刪別剗" +
+		"劊劌劍劑剮勸辦務勱動勵勁勞勢勳勻匭匱區醫華協單賣盧鹵臥衛卻巹廠廳歷厲壓厭厙廁廂厴廈廚廄廝縣參雙發變敘疊葉號嘆嘰籲嚇呂嗎" +
+		"噸聽啟吳吶嘸囈嘔嚦唄員咼嗆嗚詠嚨嚀噝響啞噠嘵嗶噦嘩噲嚌噥喲嘜嗊嘮啢嗩喚嘖嗇囀齧嘽嘯噴嘍嚳囁噯噓嚶囑嚕團園圍圇國圖圓聖壙場" +
+		"壞塊堅壇壢壩塢墳墜壟壠壚壘墾堊墊埡塏壎堝塹墮壪牆壯聲殼壺處備復夠頭誇夾奪奩奐奮獎奧妝婦媽嫵嫗媯姍薑婁婭嬈嬌孌娛媧嫻嫿嬰" +
+		"嬋嬸媼嬡嬪嬙孫學孿寧寶實寵審憲宮寬賓寢對尋導壽將爾塵堯尷屍盡層屜屆屬屢屨嶼歲豈嶇崗峴嶴嵐島嶺崬巋嶧峽嶢嶠崢巒嶗崍嶮嶄嶸嶔" +
+		"巔鞏幣帥師幃帳簾幟帶幀幫幬幘幗冪莊慶廬廡庫應廟龐廢廩開異棄張彌彎彈強歸當錄彥徹徑徠憶懺憂愾懷態慫憮慪悵愴憐總懟懌戀懇惡慟" +
+		"懨愷惻惱惲悅懸慳憫驚懼慘懲憊愜慚憚慣慍憤憒願懾懣懶戇戔戲戧戰戩戶撲執擴捫掃揚擾撫拋摶摳掄搶護報擔擬攏揀擁攔擰撥擇掛摯攣掗" +
+		"撾撻挾撓擋撟掙擠揮撏撈損撿換搗據擄摑擲撣摻摜攬搵撳攙擱摟攪攜攝攄擺搖擯攤攖撐攆擷擼攛擻攢敵斂數齋斕鬥斬斷無舊時曠暘曇晝顯" +
+		"晉曬曉曄暈暉暫曖機殺雜權條來楊榪傑極構樅樞棗櫪梘棖槍楓梟櫃檸檉梔柵標棧櫛櫳棟櫨櫟欄樹棲樣欒椏橈楨檔榿橋樺檜槳樁夢檮棶檢櫺" +
+		"槨櫝槧槶樓欖櫬櫚櫸檟檻檳櫧橫檣櫻櫫櫥櫓櫞檁歡歟歐殲歿殤殘殞殮殫殯毆毀轂畢斃氈毿氌氣氫氬氳漢湯洶溝沒灃漚瀝淪滄溈滬濘淚澩瀧" +
+		"瀘濼瀉潑澤涇潔灑窪浹淺漿澆湞濁測澮濟瀏滻渾滸濃潯濤澇淶漣潿渦溳渙滌潤澗漲澀澱淵淥漬瀆漸澠漁瀋滲溫遊灣濕潰濺漵滎灄滿瀅濾濫" +
+		"灤濱灘澦瀠瀟瀲濰潛瀦瀾瀨瀕灝滅燈靈災燦煬爐燉煒熗點煉熾爍爛烴燭煙煩燒燁燴燙燼熱煥燜燾愛爺牘犛牽犧犢狀獷獁猶狽麅獮獰獨狹" +
+		"獅獪猙獄猻獫獵獼玀豬貓蝟獻獺璣瑪瑋環現璽琺瓏璫琿璉瑣瓊瑤瓔甌甕畫暢疇癤療瘧癘瘍瘋皰痾癥癰痙癢瘂癆瘓癇痺癡癱癮癭癩癬癲皚皺" +
+		"盞鹽監蓋盜盤瞘眥矚瞞瞼矯礬礦碭碼磚硨硯碸礪礱礫礎硜碩硤磽確鹼礙磧磣禮禕禰禎禱禍祿禪離禿稈種積稱穢穠穩穡窮竊竅窯竄窩窺竇豎" +
+		"競筆筍筧箋籠籩築篳篩簽篤籌簡籃簫簀籬籮類糧糲粵糞糝緊糾紀紂約紅紆紇紈紉紋納紐紓純紕紗紙級紛紜紡紮細紱紲紳紹紺終組絆絎結絕" +
+		"絛絞絡絢給絨統絹綁綏經綜綠綢綣綫綬維綰綱網綴綸綺綻綽綾緄緇緋緒緗緘緙緝緞締緡緣緦編緩緬緯練緻縈縉縊縋縛縝縞縟縫縮縱縲縷" +
+		"縹縵績繃繅繆繚織繕繞繡繩繪繭繳繹繼續纏纓纖纜缽罌羅罰罷羆羈羋羥翹耬聳恥聶聾職聹聯聵聰肅腸膚骯餚腎腫脹脅膽勝朧臚脛膠脈膾" +
+		"臍腦膿臠腳脫臉臘醃膕齶膩靦膃騰臏艤艦艙艫艱豔藝節薌蕪蘆蓯葦藶莧萇蒼苧蘋莖蘢蔦塋煢薦薘莢蕘蓽蕎薈薺蕩榮葷犖熒蕁藎蓀蔭蕒葒葤" +
+		"藥蒞蓧萊蓮蒔萵薟獲蕕瑩鶯蓴蘀蘿螢營蕭薩蔥蕆蕢蔣蔞藍薊蘺蕷鎣驀薔蘞藺藹蘄蘊藪蘚虜慮虛蟲虯蟣雖蝦蠆蝕蟻螞蠶蠔蜆蠱蠣蟶蠻蟄蛺蟯" +
+		"螄蠐蛻蝸蠟蠅蟈蟬蠍螻蠑螿蟎蠨釁銜補襯袞襖嫋褘襪襲襏裝襠褌褳襝褲襇褸襤見觀覎規覓視覘覽覺覬覡覿覥覦覯覲覷觴觸觶訁計訂訃認譏" +
+		"訐訌討讓訕訖訓議訊記講諱謳詎訝訥許訛論訟諷設訪訣證詁訶評詛識詐訴診詆謅詞詘詔譯詒誆誄試詿詩詰詼誠誅話誕詬詮詭詢詣諍該詳詫" +
+		"諢詡誡誣語誚誤誥誘誨誑說誦誒請諸諏諾讀諑誹課諉諛誰諗調諂諒諄誶談誼謀諶諜謊諫諧謔謁謂諤諭諼讒諮諳諺諦謎諞謨讜謖謝謠謗謚謙" +
+		"謐謹謾謫譾謬譚譖譙讕譜譎讞譴譫讖貝貞負貢財責賢敗賬貨質販貪貧貶購貯貫貳賤賁貰貼貴貺貸貿費賀貽賊贄賈賄貲賃賂資賅贐賕賑賚賒" +
+		"賦賭齎贖賞賜贔賡賠賧賴贅賻賺賽賾贊贇贈贍贏贛趙趕趨趲躉躍蹌跡蹠躒踐躂蹺蹕躚躋踴躊蹤躓躑躡蹣躕躥躪躦軀車軋軌軒軔轉軛輪軟轟" +
+		"軲軻轤軸軹軼軤軫轢軺輕軾載輊轎輈輇輅較輒輔輛輦輩輝輥輞輬輟輜輳輻輯轀輸轡轅轄輾轆轍轔辭辯邊遼達遷過邁運還這進遠違連遲邇逕" +
+		"適選遜遞邐邏遺遙鄧鄺鄔郵鄒鄴鄰鬱郤郟鄶鄭鄆酈鄖鄲醞醱醬釅釃釀釋裏鑒鑾鏨釓釔針釘釗釙釕釷釧釤鈒釩釣鍆釹鍚釵鈣鈦鈍鈔鍾鈉鋇鋼" +
+		"鈑鈐鑰欽鈞鎢鉤鈧鈁鈥鈄鈕鈀鈺錢鉦鉗鈷鈳鉕鈽鈸鉞鑽鉬鉭鉀鈿鈾鐵鉑鈴鑠鉛鉚鈰鉉鉈鉍鈮鈹鐸銬銠鉺銪鋮鋏鋣鐃銍鐺銅鋁銱銦鎧鍘銖銑" +
+		"鋌銩鏵銓鎩鉿銚鉻銘錚銫鉸銥鏟銃銨銀銣鑄鐒鋪鋙錸鋱鏈鏗銷鎖鋰鋥鋤鍋鋯鋨鏽銼鋝鋒鋅鋶鐦鐧銳銻鋃鋟鋦錒錆鍺錯錨錛錡錁錕錩錫錮鑼" +
+		"錘錐錦鍁錈錇錟鍵鋸錳錙鍥鍈鍇鏘鍶鍔鍤鍬鍛鎪鍠鍰鎄鍍鎂鏤鐨鎇鏌鎮鎛鎘鑷鎳鎿鎦鎬鎊鎰鎵鑌鏢鏜鏝鏍鏞鏡鏑鏃鏇鏐鐔鐐鏷鑥鐓鑭鐠鑹" +
+		"鏹鐙鑊鐳鐶鐲鐮鐿鑔鑣鑲長門閂閃閆閈閉問闖閏闈閑閎間閔閌悶閘鬧閨聞闥閩閭闓閥閣閡閫鬮閱閬闍閾閹閶鬩閿閽閻閼闡闌闃闠闊闕闔" +
+		"闐闢闞闋闤隊陽陰陣階際陸隴陳陘陝隉隕險隨隱隸雋難雛讎靂霧霽黴靄靚靜靨韃鞽韉韋韌韍韓韙韜韞韻頁頂頃項順須頊頑顧頓頎頒頌頏預" +
+		"顱領頗頸頡頰頜潁頦頤頻頹頷穎顆題顒顎顓顏額顳顢顛顙顥顫顰顴風颺颭颮颯颶颸颼飄飆飛饗饜飣飢餳飩餼飪飫飭飯飲餞飾飽飼飿餃餄餅" +
+		"餉餌餎餏餑餒餓餕餖餛餡館餷饋餶餿饞饃餺餾饈饉饅饊饌饢馬馭馱馴馳驅駁驢駔駛駟駙駒騶駐駝駑駕驛駘驍罵駱驊駭駢驫驪騁驗駿騏騎騍" +
+		"騅驂騙騭騷騖驁騮騫騸驃騾驄驏驟驥驤髏髖髕鬢魘魎魚魯鮑鮮鯉鯨鯊鰻鱷鳥鳩雞鳴鴉鴨鴛鴦鴻鵝鵡鵬鶴鷹鸚鷺鸞鹹麥麩黃黌黷黲黽黿鼉鼴" +
+		"齊齏齒齔齣齟齠齡齙齜齪齬齲齷龍龔龕龜劇電臺麵蘇鬆鬍隻準絃週彙範髒嚮餵幹穀嶽捲僕樸蒐衊製迴係闆佈" +
+		"嘗脩贗"
+	simplified = "" +
+		"万与丑专业丛东丝两严丧个丰临为丽举么义乌乐乔习乡书买乱争亏云亚产亩亲亿仅从仑仓仪们价众优会伞伟传伤伦伪体余佣佥侠侣侥" +
+		"侦侧侨侩侪侬俣俦俨俩俪俭债倾偻偾偿傥傧储傩儿兑党兰关兴养兽内冈册写军农冯冲决况冻净凉减凑凛几凤凭凯击凿刍划刘则刚创删别刬" +
+		"刽刿剑剂剐劝办务劢动励劲劳势勋匀匦匮区医华协单卖卢卤卧卫却卺厂厅历厉压厌厍厕厢厣厦厨厩厮县参双发变叙叠叶号叹叽吁吓吕吗" +
+		"吨听启吴呐呒呓呕呖呗员呙呛呜咏咙咛咝响哑哒哓哔哕哗哙哜哝哟唛唝唠唡唢唤啧啬啭啮啴啸喷喽喾嗫嗳嘘嘤嘱噜团园围囵国图圆圣圹场" +
+		"坏块坚坛坜坝坞坟坠垄垅垆垒垦垩垫垭垲埙埚堑堕塆墙壮声壳壶处备复够头夸夹夺奁奂奋奖奥妆妇妈妩妪妫姗姜娄娅娆娇娈娱娲娴婳婴" +
+		"婵婶媪嫒嫔嫱孙学孪宁宝实宠审宪宫宽宾寝对寻导寿将尔尘尧尴尸尽层屉届属屡屦屿岁岂岖岗岘岙岚岛岭岽岿峄峡峣峤峥峦崂崃崄崭嵘嵚" +
+		"巅巩币帅师帏帐帘帜带帧帮帱帻帼幂庄庆庐庑库应庙庞废廪开异弃张弥弯弹强归当录彦彻径徕忆忏忧忾怀态怂怃怄怅怆怜总怼怿恋恳恶恸" +
+		"恹恺恻恼恽悦悬悭悯惊惧惨惩惫惬惭惮惯愠愤愦愿慑懑懒戆戋戏戗战戬户扑执扩扪扫扬扰抚抛抟抠抡抢护报担拟拢拣拥拦拧拨择挂挚挛挜" +
+		"挝挞挟挠挡挢挣挤挥挦捞损捡换捣据掳掴掷掸掺掼揽揾揿搀搁搂搅携摄摅摆摇摈摊撄撑撵撷撸撺擞攒敌敛数斋斓斗斩断无旧时旷旸昙昼显" +
+		"晋晒晓晔晕晖暂暧机杀杂权条来杨杩杰极构枞枢枣枥枧枨枪枫枭柜柠柽栀栅标栈栉栊栋栌栎栏树栖样栾桠桡桢档桤桥桦桧桨桩梦梼梾检棂" +
+		"椁椟椠椢楼榄榇榈榉槚槛槟槠横樯樱橥橱橹橼檩欢欤欧歼殁殇残殒殓殚殡殴毁毂毕毙毡毵氇气氢氩氲汉汤汹沟没沣沤沥沦沧沩沪泞泪泶泷" +
+		"泸泺泻泼泽泾洁洒洼浃浅浆浇浈浊测浍济浏浐浑浒浓浔涛涝涞涟涠涡涢涣涤润涧涨涩淀渊渌渍渎渐渑渔沈渗温游湾湿溃溅溆荥滠满滢滤滥" +
+		"滦滨滩滪潆潇潋潍潜潴澜濑濒灏灭灯灵灾灿炀炉炖炜炝点炼炽烁烂烃烛烟烦烧烨烩烫烬热焕焖焘爱爷牍牦牵牺犊状犷犸犹狈狍狝狞独狭" +
+		"狮狯狰狱狲猃猎猕猡猪猫猬献獭玑玛玮环现玺珐珑珰珲琏琐琼瑶璎瓯瓮画畅畴疖疗疟疠疡疯疱疴症痈痉痒痖痨痪痫痹痴瘫瘾瘿癞癣癫皑皱" +
+		"盏盐监盖盗盘眍眦瞩瞒睑矫矾矿砀码砖砗砚砜砺砻砾础硁硕硖硗确碱碍碛碜礼祎祢祯祷祸禄禅离秃秆种积称秽秾稳穑穷窃窍窑窜窝窥窦竖" +
+		"竞笔笋笕笺笼笾筑筚筛签笃筹简篮箫箦篱箩类粮粝粤粪糁紧纠纪纣约红纡纥纨纫纹纳纽纾纯纰纱纸级纷纭纺扎细绂绁绅绍绀终组绊绗结绝" +
+		"绦绞络绚给绒统绢绑绥经综绿绸绻线绶维绾纲网缀纶绮绽绰绫绲缁绯绪缃缄缂缉缎缔缗缘缌编缓缅纬练致萦缙缢缒缚缜缟缛缝缩纵缧缕" +
+		"缥缦绩绷缫缪缭织缮绕绣绳绘茧缴绎继续缠缨纤缆钵罂罗罚罢罴羁芈羟翘耧耸耻聂聋职聍联聩聪肃肠肤肮肴肾肿胀胁胆胜胧胪胫胶脉脍" +
+		"脐脑脓脔脚脱脸腊腌腘腭腻腼腽腾膑舣舰舱舻艰艳艺节芗芜芦苁苇苈苋苌苍苎苹茎茏茑茔茕荐荙荚荛荜荞荟荠荡荣荤荦荧荨荩荪荫荬荭荮" +
+		"药莅莜莱莲莳莴莶获莸莹莺莼萚萝萤营萧萨葱蒇蒉蒋蒌蓝蓟蓠蓣蓥蓦蔷蔹蔺蔼蕲蕴薮藓虏虑虚虫虬虮虽虾虿蚀蚁蚂蚕蚝蚬蛊蛎蛏蛮蛰蛱蛲" +
+		"蛳蛴蜕蜗蜡蝇蝈蝉蝎蝼蝾螀螨蟏衅衔补衬衮袄袅袆袜袭袯装裆裈裢裣裤裥褛褴见观觃规觅视觇览觉觊觋觌觍觎觏觐觑觞触觯讠计订讣认讥" +
+		"讦讧讨让讪讫训议讯记讲讳讴讵讶讷许讹论讼讽设访诀证诂诃评诅识诈诉诊诋诌词诎诏译诒诓诔试诖诗诘诙诚诛话诞诟诠诡询诣诤该详诧" +
+		"诨诩诫诬语诮误诰诱诲诳说诵诶请诸诹诺读诼诽课诿谀谁谂调谄谅谆谇谈谊谋谌谍谎谏谐谑谒谓谔谕谖谗谘谙谚谛谜谝谟谠谡谢谣谤谥谦" +
+		"谧谨谩谪谫谬谭谮谯谰谱谲谳谴谵谶贝贞负贡财责贤败账货质贩贪贫贬购贮贯贰贱贲贳贴贵贶贷贸费贺贻贼贽贾贿赀赁赂资赅赆赇赈赉赊" +
+		"赋赌赍赎赏赐赑赓赔赕赖赘赙赚赛赜赞赟赠赡赢赣赵赶趋趱趸跃跄迹跖跞践跶跷跸跹跻踊踌踪踬踯蹑蹒蹰蹿躏躜躯车轧轨轩轫转轭轮软轰" +
+		"轱轲轳轴轵轶轷轸轹轺轻轼载轾轿辀辁辂较辄辅辆辇辈辉辊辋辌辍辎辏辐辑辒输辔辕辖辗辘辙辚辞辩边辽达迁过迈运还这进远违连迟迩迳" +
+		"适选逊递逦逻遗遥邓邝邬邮邹邺邻郁郄郏郐郑郓郦郧郸酝酦酱酽酾酿释里鉴銮錾钆钇针钉钊钋钌钍钏钐钑钒钓钔钕钖钗钙钛钝钞钟钠钡钢" +
+		"钣钤钥钦钧钨钩钪钫钬钭钮钯钰钱钲钳钴钶钷钸钹钺钻钼钽钾钿铀铁铂铃铄铅铆铈铉铊铋铌铍铎铐铑铒铕铖铗铘铙铚铛铜铝铞铟铠铡铢铣" +
+		"铤铥铧铨铩铪铫铬铭铮铯铰铱铲铳铵银铷铸铹铺铻铼铽链铿销锁锂锃锄锅锆锇锈锉锊锋锌锍锎锏锐锑锒锓锔锕锖锗错锚锛锜锞锟锠锡锢锣" +
+		"锤锥锦锨锩锫锬键锯锰锱锲锳锴锵锶锷锸锹锻锼锽锾锿镀镁镂镄镅镆镇镈镉镊镍镎镏镐镑镒镓镔镖镗镘镙镛镜镝镞镟镠镡镣镤镥镦镧镨镩" +
+		"镪镫镬镭镮镯镰镱镲镳镶长门闩闪闫闬闭问闯闰闱闲闳间闵闶闷闸闹闺闻闼闽闾闿阀阁阂阃阄阅阆阇阈阉阊阋阌阍阎阏阐阑阒阓阔阙阖" +
+		"阗辟阚阕阛队阳阴阵阶际陆陇陈陉陕陧陨险随隐隶隽难雏雠雳雾霁霉霭靓静靥鞑鞒鞯韦韧韨韩韪韬韫韵页顶顷项顺须顼顽顾顿颀颁颂颃预" +
+		"颅领颇颈颉颊颌颍颏颐频颓颔颖颗题颙颚颛颜额颞颟颠颡颢颤颦颧风飏飐飑飒飓飔飕飘飙飞飨餍饤饥饧饨饩饪饫饬饭饮饯饰饱饲饳饺饸饼" +
+		"饷饵饹饻饽馁饿馂饾馄馅馆馇馈馉馊馋馍馎馏馐馑馒馓馔馕马驭驮驯驰驱驳驴驵驶驷驸驹驺驻驼驽驾驿骀骁骂骆骅骇骈骉骊骋验骏骐骑骒" +
+		"骓骖骗骘骚骛骜骝骞骟骠骡骢骣骤骥骧髅髋髌鬓魇魉鱼鲁鲍鲜鲤鲸鲨鳗鳄鸟鸠鸡鸣鸦鸭鸳鸯鸿鹅鹉鹏鹤鹰鹦鹭鸾咸麦麸黄黉黩黪黾鼋鼍鼹" +
+		"齐齑齿龀出龃龆龄龅龇龊龉龋龌龙龚龛龟剧电台面苏松胡只准弦周汇范脏向喂干谷岳卷仆朴搜蔑制回系板布" +
+		"尝修赝"
+)
+
+var t2s = func() map[rune]rune {
+	t, s := []rune(traditional), []rune(simplified)
+	m := make(map[rune]rune, len(t))
+	for i := range t {
+		m[t[i]] = s[i]
+	}
+	return m
+}()
+
+var (
+	// RomanRe 季、部等关键词后的罗马数字，如 Season II、Part IV
+	RomanRe = regexp.MustCompile(`\b((?i:season|part|pt|vol|volume|chapter|episode|book)\s+)(X{0,3}(?:IX|IV|V?I{0,3}))\b`)
+	// RomanTailRe 标题末尾的罗马数字，如 Rocky II
+	RomanTailRe = regexp.MustCompile(`(\s)(X{0,3}(?:IX|IV|V?I{0,3}))\s*$`)
+	// CnNumeralRe 第x季、第x部、第x集中的中文数字
+	CnNumeralRe = regexp.MustCompile(`(第)([零一二三四五六七八九十百千万两]+)([季部集话話期])`)
+	PunctRe     = regexp.MustCompile(`[\p{P}\p{S}]+`)
+	SpaceRe     = regexp.MustCompile(`\s+`)
+)
+
+// ToSimplified 繁体转简体，不在对照表中的字保持不变
+func ToSimplified(s string) string {
+	return strings.Map(func(r rune) rune {
+		if v, ok := t2s[r]; ok {
+			return v
+		}
+		return r
+	}, s)
+}
+
+// ToHalfWidth 全角字符转半角
+func ToHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u3000':
+			return ' '
+		case r >= '\uff01' && r <= '\uff5e':
+			return r - 0xfee0
+		}
+		return r
+	}, s)
+}
+
+// FoldPunct 标点、符号统一为空格，& 转为 and，× 转为 x
+func FoldPunct(s string) string {
+	s = strings.NewReplacer("&", " and ", "×", " x ").Replace(s)
+	// 撇号直接去掉，Director's 与 Directors 一致
+	s = strings.NewReplacer("'", "", "’", "").Replace(s)
+	return PunctRe.ReplaceAllString(s, " ")
+}
+
+// FoldNumerals 罗马数字和中文数字转为阿拉伯数字。罗马数字只处理季、部等关键词后和标题末尾的大写单词，
+// I Am Legend 中的 I 保持不变；中文数字只处理第x季、第x部、第x集
+func FoldNumerals(s string) string {
+	foldRoman := func(m []string) string {
+		if m[2] == "" {
+			return m[0]
+		}
+		return m[1] + strconv.Itoa(romanToInt(m[2]))
+	}
+	s = replaceSubmatch(RomanRe, s, foldRoman)
+	s = replaceSubmatch(RomanTailRe, s, foldRoman)
+	return replaceSubmatch(CnNumeralRe, s, func(m []string) string {
+		if v := utils.CnToNumber(m[2], -1); v >= 0 {
+			return m[1] + strconv.FormatInt(v, 10) + m[3]
+		}
+		return m[0]
+	})
+}
+
+// replaceSubmatch 与 ReplaceAllStringFunc 相同，但回调参数为分组
+func replaceSubmatch(re *regexp.Regexp, s string, fn func(m []string) string) string {
+	locs := re.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = s[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(fn(m))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func romanToInt(s string) int {
+	values := map[byte]int{'I': 1, 'V': 5, 'X': 10}
+	total := 0
+	for i := 0; i < len(s); i++ {
+		v := values[s[i]]
+		if i+1 < len(s) && v < values[s[i+1]] {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	return total
+}
+
+// Key 生成用于比较的标题，依次做全角转半角、繁转简、标点折叠、数字折叠、小写，
+// 并去掉中日韩文字两侧的空格
+func Key(title string) string {
+	s := ToHalfWidth(title)
+	s = ToSimplified(s)
+	s = FoldPunct(s)
+	s = FoldNumerals(s)
+	s = strings.ToLower(s)
+	s = strings.TrimSpace(SpaceRe.ReplaceAllString(s, " "))
+	return trimCJKSpace(s)
+}
+
+// Equal 两个标题规范化后是否相同
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// Similarity 规范化后按编辑距离计算相似度，1 为相同，0 为完全不同
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(Key(a)), []rune(Key(b))
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	if string(ra) == string(rb) {
+		return 1
+	}
+	n := len(ra)
+	if len(rb) > n {
+		n = len(rb)
+	}
+	return 1 - float64(distance(ra, rb))/float64(n)
+}
+
+// distance 编辑距离
+func distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func trimCJKSpace(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == ' ' && i > 0 && i < len(runes)-1 && (isCJK(runes[i-1]) || isCJK(runes[i+1])) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,112 @@
+package normalize
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"流浪地球２", "流浪地球2"},
+		{"進擊的巨人", "进击的巨人"},
+		{"名著", "名著"},
+		{"乾坤", "乾坤"},
+		{"臺灣", "台湾"},
+		{"Spider-Man: No Way Home", "spider man no way home"},
+		{"Director's Cut", "directors cut"},
+		{"Fast & Furious", "fast and furious"},
+		{"哈利·波特：魔法石", "哈利波特魔法石"},
+		{"Rocky II", "rocky 2"},
+		{"Star Wars Episode IV", "star wars episode 4"},
+		{"Harry Potter Part II", "harry potter part 2"},
+		{"The Godfather Part III 1990", "the godfather part 3 1990"},
+		{"I Am Legend", "i am legend"},
+		{"V for Vendetta", "v for vendetta"},
+		{"Generation X Files", "generation x files"},
+		{"Season of the Witch", "season of the witch"},
+		{"进击的巨人 第二季", "进击的巨人第2季"},
+		{"庆余年第二部", "庆余年第2部"},
+		{"一人之下", "一人之下"},
+		{"万万没想到", "万万没想到"},
+		{"十二国记", "十二国记"},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.title); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestToSimplified(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"權力的遊戲", "权力的游戏"},
+		{"鬼滅之刃", "鬼灭之刃"},
+		// 髮、著、乾 等不是一对一的字不转换
+		{"頭髮", "头髮"},
+		{"著名", "著名"},
+		{"乾淨", "乾净"},
+		{"於是", "於是"},
+	}
+	for _, tt := range tests {
+		if got := ToSimplified(tt.s); got != tt.want {
+			t.Errorf("ToSimplified(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestT2SOneToOne(t *testing.T) {
+	if len([]rune(traditional)) != len([]rune(simplified)) {
+		t.Fatalf("traditional %d and simplified %d differ in length", len([]rune(traditional)), len([]rune(simplified)))
+	}
+	sources := make(map[rune]rune)
+	for tr, s := range t2s {
+		if tr == s {
+			t.Errorf("%c maps to itself", tr)
+		}
+		if other, ok := sources[s]; ok {
+			t.Errorf("%c and %c both map to %c", other, tr, s)
+		}
+		sources[s] = tr
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"流浪地球", "流浪地球", true},
+		{"鬼滅之刃", "鬼灭之刃", true},
+		{"ＳＰＹ×ＦＡＭＩＬＹ", "Spy x Family", true},
+		{"Rocky II", "Rocky 2", true},
+		{"进击的巨人 第二季", "进击的巨人第2季", true},
+		{"I Am Legend", "1 Am Legend", false},
+		{"流浪地球", "流浪地球2", false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"流浪地球", "流浪地球", 1, 1},
+		{"", "流浪地球", 0, 0},
+		{"流浪地球", "流浪地球2", 0.7, 0.9},
+		{"The Wandering Earth", "Wandering Earth", 0.7, 0.9},
+		{"流浪地球", "Inception", 0, 0.1},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %.2f, want [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
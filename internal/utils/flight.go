@@ -0,0 +1,41 @@
+package utils
+
+import "sync"
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Flight 合并相同 key 的并发调用，零值可用
+type Flight struct {
+	lock  sync.Mutex
+	calls map[string]*flightCall
+}
+
+// Do 同一 key 同时只执行一次 fn，其余调用等待并共享结果，shared 表示结果来自其他调用
+func (f *Flight) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	f.lock.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]*flightCall)
+	}
+	if c, ok := f.calls[key]; ok {
+		f.lock.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := new(flightCall)
+	c.wg.Add(1)
+	f.calls[key] = c
+	f.lock.Unlock()
+
+	defer func() {
+		f.lock.Lock()
+		delete(f.calls, key)
+		f.lock.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
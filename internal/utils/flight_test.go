@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightDo(t *testing.T) {
+	var f Flight
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	fn := func() (interface{}, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "value", nil
+	}
+
+	const n = 8
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	results := make([]interface{}, n)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _, _ = f.Do("key", fn)
+	}()
+	<-started
+	var entered sync.WaitGroup
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		entered.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entered.Done()
+			var s bool
+			results[i], _, s = f.Do("key", fn)
+			if s {
+				shared.Add(1)
+			}
+		}(i)
+	}
+	// 等待其余调用进入等待后再放行
+	entered.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 || shared.Load() != n-1 {
+		t.Errorf("fn called %d times, shared %d, want 1, %d", calls.Load(), shared.Load(), n-1)
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("result %d = %v", i, v)
+		}
+	}
+
+	// 调用结束后不再共享，错误原样返回
+	want := errors.New("failed")
+	if _, err, s := f.Do("key", func() (interface{}, error) { return nil, want }); err != want || s {
+		t.Errorf("Do = %v %v, want %v false", err, s, want)
+	}
+	if calls.Load() != 1 || len(f.calls) != 0 {
+		t.Errorf("Do left %d calls", len(f.calls))
+	}
+}
+
+func TestFlightKeys(t *testing.T) {
+	var f Flight
+	v1, _, _ := f.Do("a", func() (interface{}, error) { return 1, nil })
+	v2, _, _ := f.Do("b", func() (interface{}, error) { return 2, nil })
+	if v1 != 1 || v2 != 2 {
+		t.Errorf("Do = %v %v, want 1 2", v1, v2)
+	}
+}
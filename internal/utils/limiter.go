@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限流，rate 为每秒令牌数，burst 为桶容量
+type RateLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter rate 小于等于 0 时不限流，返回 nil
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 取一个令牌，令牌不足时预支并等待补足
+func (l *RateLimiter) Wait() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.lock.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
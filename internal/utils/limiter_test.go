@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if l := NewRateLimiter(0, 10); l != nil {
+		t.Errorf("NewRateLimiter(0) = %+v, want nil", l)
+	}
+	var l *RateLimiter
+	l.Wait()
+
+	tests := []struct {
+		rate  float64
+		burst int
+		n     int
+		min   time.Duration // n 次 Wait 至少耗时
+		max   time.Duration
+	}{
+		{100, 5, 5, 0, 20 * time.Millisecond},
+		{100, 5, 8, 30 * time.Millisecond, 200 * time.Millisecond},
+		{100, 0, 3, 20 * time.Millisecond, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.rate, tt.burst)
+		start := time.Now()
+		for i := 0; i < tt.n; i++ {
+			l.Wait()
+		}
+		if d := time.Since(start); d < tt.min || d > tt.max {
+			t.Errorf("NewRateLimiter(%v, %d) %d waits took %s, want %s~%s", tt.rate, tt.burst, tt.n, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(100, 2)
+	l.Wait()
+	l.Wait()
+	// 空闲后令牌补足，但不超过 burst
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	l.Wait()
+	l.Wait()
+	if d := time.Since(start); d > 5*time.Millisecond {
+		t.Errorf("waits after refill took %s", d)
+	}
+	l.Wait()
+	if d := time.Since(start); d < 5*time.Millisecond {
+		t.Errorf("wait beyond burst took %s", d)
+	}
+}
@@ -6,18 +6,53 @@ import (
 	"strings"
 )
 
+// IsChinese 包含汉字且不含假名、谚文
 func IsChinese(s string) bool {
+	han := false
 	for _, r := range s {
-		if r == ' ' {
-			continue
+		if isKana(r) || isHangul(r) {
+			return false
 		}
 		if r >= '\u4e00' && r <= '\u9fff' {
+			han = true
+		}
+	}
+	return han
+}
+
+// IsJapanese 包含平假名或片假名
+func IsJapanese(s string) bool {
+	for _, r := range s {
+		if isKana(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKorean 包含谚文
+func IsKorean(s string) bool {
+	for _, r := range s {
+		if isHangul(r) {
 			return true
 		}
 	}
 	return false
 }
 
+func isKana(r rune) bool {
+	return r >= '\u3041' && r <= '\u309f' || // 平假名
+		r >= '\u30a0' && r <= '\u30ff' && r != '\u30fb' || // 片假名，不含中点・
+		r >= '\u31f0' && r <= '\u31ff' || // 片假名音标扩展
+		r >= '\uff66' && r <= '\uff9d' // 半角片假名
+}
+
+func isHangul(r rune) bool {
+	return r >= '\uac00' && r <= '\ud7af' || // 谚文音节
+		r >= '\u1100' && r <= '\u11ff' || // 谚文字母
+		r >= '\u3130' && r <= '\u318f' // 谚文兼容字母
+}
+
 func InList(s string, list []string, upper bool) bool {
 	if upper {
 		s = strings.ToUpper(s)
@@ -38,6 +38,14 @@ func (t *Tokenizer) Peek() (string, error) {
 	return "", io.EOF
 }
 
+// PeekN 向后查看第 n 个 token
+func (t *Tokenizer) PeekN(n int) (string, error) {
+	if t.index+n < len(t.tokens) {
+		return t.tokens[t.index+n], nil
+	}
+	return "", io.EOF
+}
+
 func (t *Tokenizer) Cur() (string, error) {
 	if t.index < len(t.tokens) {
 		return t.tokens[t.index], nil
@@ -45,6 +53,10 @@ func (t *Tokenizer) Cur() (string, error) {
 	return "", io.EOF
 }
 
+func (t *Tokenizer) Index() int {
+	return t.index
+}
+
 func (t *Tokenizer) Reset() {
 	t.index = 0
 }
@@ -1,20 +1,365 @@
 package utils
 
+import (
+	"errors"
+	"fmt"
+	"github.com/dlclark/regexp2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WordTypeIgnore        = iota + 1 // 屏蔽词
+	WordTypeReplace                  // 替换词
+	WordTypeOffset                   // 集偏移词
+	WordTypeReplaceOffset            // 替换词+集偏移词
+)
+
+const (
+	wordReplaceSep = " => "
+	wordOffsetSep  = " >> "
+	wordLocateSep  = " <> "
+	wordJoinSep    = " && "
+)
+
+// WordMatchTimeout 识别词正则单次匹配的超时，避免回溯过多的规则卡住每次识别
+const WordMatchTimeout = 100 * time.Millisecond
+
+var (
+	ErrWordEmpty  = errors.New("word is empty")
+	ErrWordFormat = errors.New("word format error")
+)
+
 type ProcessInfo struct {
 	Replaced []string
 	Ignored  []string
 	Offset   []string
 }
 
+// Word 自定义识别词
+//
+//	屏蔽词：  A
+//	替换词：  A => B
+//	集偏移：  前定位词 <> 后定位词 >> EP+12
+//	替换+集偏移：A => B && 前定位词 <> 后定位词 >> EP+12
+type Word struct {
+	Type    int
+	Text    string // 原始识别词
+	Word    string // 屏蔽词、被替换词
+	Replace string // 替换词
+	Front   string // 前定位词
+	Back    string // 后定位词
+	Offset  string // 偏移表达式
+	Regex   bool   // 是否正则
+	wordRe  *regexp2.Regexp
+	epRe    *regexp2.Regexp
+}
+
+// ParseWord 解析识别词
+func ParseWord(text string, regex bool) (*Word, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrWordEmpty
+	}
+	w := &Word{Text: text, Regex: regex}
+	word, offset, hasOffset := strings.Cut(text, wordJoinSep)
+	if !hasOffset && strings.Contains(text, wordOffsetSep) && !strings.Contains(text, wordReplaceSep) {
+		word, offset, hasOffset = "", text, true
+	}
+	if word != "" {
+		if from, to, ok := strings.Cut(word, wordReplaceSep); ok {
+			w.Type = WordTypeReplace
+			w.Word = strings.TrimSpace(from)
+			w.Replace = strings.TrimSpace(to)
+		} else {
+			w.Type = WordTypeIgnore
+			w.Word = word
+		}
+		if w.Word == "" {
+			return nil, ErrWordFormat
+		}
+		pattern := w.Word
+		if !regex {
+			pattern = regexp2.Escape(pattern)
+		}
+		re, err := compileWord(pattern)
+		if err != nil {
+			return nil, err
+		}
+		w.wordRe = re
+	}
+	if hasOffset {
+		if err := w.parseOffset(offset); err != nil {
+			return nil, err
+		}
+		if w.Type == WordTypeReplace {
+			w.Type = WordTypeReplaceOffset
+		} else if w.Type == WordTypeIgnore {
+			return nil, ErrWordFormat
+		} else {
+			w.Type = WordTypeOffset
+		}
+	}
+	return w, nil
+}
+
+// compileWord 编译识别词正则并设置匹配超时
+func compileWord(pattern string) (*regexp2.Regexp, error) {
+	re, err := regexp2.Compile(pattern, regexp2.None)
+	if err != nil {
+		return nil, err
+	}
+	re.MatchTimeout = WordMatchTimeout
+	return re, nil
+}
+
+func (w *Word) parseOffset(text string) error {
+	locate, offset, ok := strings.Cut(text, wordOffsetSep)
+	if !ok {
+		return ErrWordFormat
+	}
+	front, back, ok := strings.Cut(locate, wordLocateSep)
+	if !ok {
+		return ErrWordFormat
+	}
+	w.Front = strings.TrimSpace(front)
+	w.Back = strings.TrimSpace(back)
+	w.Offset = strings.TrimSpace(offset)
+	if !strings.Contains(strings.ToUpper(w.Offset), "EP") {
+		return ErrWordFormat
+	}
+	if _, err := evalOffset(w.Offset, 1); err != nil {
+		return err
+	}
+	front, back = w.Front, w.Back
+	if !w.Regex {
+		front, back = regexp2.Escape(front), regexp2.Escape(back)
+	}
+	pattern := `[0-9一二三四五六七八九十百零]+`
+	if front != "" {
+		pattern = fmt.Sprintf(`(?<=%s.*?)%s`, front, pattern)
+	}
+	if back != "" {
+		pattern = fmt.Sprintf(`%s(?=.*?%s)`, pattern, back)
+	}
+	re, err := compileWord(pattern)
+	if err != nil {
+		return err
+	}
+	w.epRe = re
+	return nil
+}
+
+// apply 对标题应用识别词，返回处理后的标题和是否命中
+func (w *Word) apply(title string) (string, bool, error) {
+	switch w.Type {
+	case WordTypeIgnore:
+		if ok, err := w.wordRe.MatchString(title); !ok || err != nil {
+			return title, false, err
+		}
+		t, err := w.wordRe.Replace(title, "", -1, -1)
+		return strings.TrimSpace(t), err == nil, err
+	case WordTypeReplace:
+		if ok, err := w.wordRe.MatchString(title); !ok || err != nil {
+			return title, false, err
+		}
+		t, err := w.wordRe.Replace(title, w.Replace, -1, -1)
+		return t, err == nil, err
+	case WordTypeOffset:
+		return w.applyOffset(title)
+	case WordTypeReplaceOffset:
+		if ok, err := w.wordRe.MatchString(title); !ok || err != nil {
+			return title, false, err
+		}
+		t, err := w.wordRe.Replace(title, w.Replace, -1, -1)
+		if err != nil {
+			return title, false, err
+		}
+		t, _, err = w.applyOffset(t)
+		return t, err == nil, err
+	}
+	return title, false, nil
+}
+
+func (w *Word) applyOffset(title string) (string, bool, error) {
+	if ok, err := w.epRe.MatchString(title); !ok || err != nil {
+		return title, false, err
+	}
+	var evalErr error
+	t, err := w.epRe.ReplaceFunc(title, func(m regexp2.Match) string {
+		ep := m.String()
+		num := CnToNumber(ep, -1)
+		if num < 0 {
+			return ep
+		}
+		val, err := evalOffset(w.Offset, num)
+		if err != nil {
+			evalErr = err
+			return ep
+		}
+		if val < 0 {
+			val = 0
+		}
+		// 数字保持原有位数，如 05 -> 17
+		width := 0
+		if _, err := strconv.Atoi(ep); err == nil {
+			width = len(ep)
+		}
+		return fmt.Sprintf("%0*d", width, val)
+	}, -1, -1)
+	if err != nil {
+		return title, false, err
+	}
+	if evalErr != nil {
+		return title, false, evalErr
+	}
+	return t, true, nil
+}
+
+// evalOffset 计算集偏移表达式，支持 + - * / 和括号，EP 为原集数
+func evalOffset(expr string, ep int64) (int64, error) {
+	expr = strings.ReplaceAll(strings.ToUpper(expr), "EP", strconv.FormatInt(ep, 10))
+	e := &offsetExpr{s: strings.ReplaceAll(expr, " ", "")}
+	val, err := e.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if e.pos != len(e.s) {
+		return 0, ErrWordFormat
+	}
+	return val, nil
+}
+
+type offsetExpr struct {
+	s   string
+	pos int
+}
+
+func (e *offsetExpr) parseExpr() (int64, error) {
+	val, err := e.parseTerm()
+	for err == nil && e.pos < len(e.s) && (e.s[e.pos] == '+' || e.s[e.pos] == '-') {
+		op := e.s[e.pos]
+		e.pos++
+		var rhs int64
+		if rhs, err = e.parseTerm(); err == nil {
+			if op == '+' {
+				val += rhs
+			} else {
+				val -= rhs
+			}
+		}
+	}
+	return val, err
+}
+
+func (e *offsetExpr) parseTerm() (int64, error) {
+	val, err := e.parseFactor()
+	for err == nil && e.pos < len(e.s) && (e.s[e.pos] == '*' || e.s[e.pos] == '/') {
+		op := e.s[e.pos]
+		e.pos++
+		var rhs int64
+		if rhs, err = e.parseFactor(); err == nil {
+			if op == '*' {
+				val *= rhs
+			} else if rhs == 0 {
+				err = ErrWordFormat
+			} else {
+				val /= rhs
+			}
+		}
+	}
+	return val, err
+}
+
+func (e *offsetExpr) parseFactor() (int64, error) {
+	if e.pos >= len(e.s) {
+		return 0, ErrWordFormat
+	}
+	switch c := e.s[e.pos]; {
+	case c == '(':
+		e.pos++
+		val, err := e.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if e.pos >= len(e.s) || e.s[e.pos] != ')' {
+			return 0, ErrWordFormat
+		}
+		e.pos++
+		return val, nil
+	case c == '-':
+		e.pos++
+		val, err := e.parseFactor()
+		return -val, err
+	case c >= '0' && c <= '9':
+		start := e.pos
+		for e.pos < len(e.s) && e.s[e.pos] >= '0' && e.s[e.pos] <= '9' {
+			e.pos++
+		}
+		return strconv.ParseInt(e.s[start:e.pos], 10, 64)
+	}
+	return 0, ErrWordFormat
+}
+
 var wp = newWordProcess()
 
 type WordProcess struct {
+	sync.RWMutex
+	words []*Word
 }
 
 func newWordProcess() *WordProcess {
 	return &WordProcess{}
 }
 
+// Load 替换全部识别词，可在运行时重复调用
+func (p *WordProcess) Load(words []*Word) {
+	p.Lock()
+	defer p.Unlock()
+	p.words = words
+}
+
+func (p *WordProcess) Process(w string) (rw string, info ProcessInfo, err error) {
+	rw = w
+	if w == "" {
+		return
+	}
+	p.RLock()
+	words := p.words
+	p.RUnlock()
+	for _, word := range words {
+		t, ok, e := word.apply(rw)
+		if e != nil {
+			err = fmt.Errorf("word %s: %w", word.Text, e)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		rw = t
+		switch word.Type {
+		case WordTypeIgnore:
+			info.Ignored = append(info.Ignored, word.Text)
+		case WordTypeReplace:
+			info.Replaced = append(info.Replaced, word.Text)
+		case WordTypeOffset:
+			info.Offset = append(info.Offset, word.Text)
+		case WordTypeReplaceOffset:
+			info.Replaced = append(info.Replaced, word.Text)
+			info.Offset = append(info.Offset, word.Text)
+		}
+	}
+	return
+}
+
+// LoadWords 热加载识别词
+func LoadWords(words []*Word) {
+	wp.Load(words)
+}
+
+// ProcessTitle 使用识别词处理标题
 func ProcessTitle(w string) (rw string, info ProcessInfo, err error) {
-	return w, ProcessInfo{}, nil
+	return wp.Process(w)
 }
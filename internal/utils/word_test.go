@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWord(t *testing.T) {
+	tests := []struct {
+		text  string
+		regex bool
+		typ   int
+		err   error
+	}{
+		{"CHS", false, WordTypeIgnore, nil},
+		{"Spy.x.Family => 间谍过家家", false, WordTypeReplace, nil},
+		{"第 <> 集 >> EP-12", false, WordTypeOffset, nil},
+		{"Show2 => Show && E <> . >> EP+10", false, WordTypeReplaceOffset, nil},
+		{"  ", false, 0, ErrWordEmpty},
+		{"a <> b >> 12", false, 0, ErrWordFormat},
+		{"a <> b >> EP/0", false, 0, ErrWordFormat},
+		{"a >> EP+1", false, 0, ErrWordFormat},
+		{"a && b", false, 0, ErrWordFormat},
+	}
+	for _, tt := range tests {
+		w, err := ParseWord(tt.text, tt.regex)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseWord(%q) error %v, want %v", tt.text, err, tt.err)
+			continue
+		}
+		if err == nil && w.Type != tt.typ {
+			t.Errorf("ParseWord(%q) type %d, want %d", tt.text, w.Type, tt.typ)
+		}
+	}
+	if _, err := ParseWord("(", true); err == nil {
+		t.Errorf("ParseWord with invalid regex succeeded")
+	}
+}
+
+func TestWordProcess(t *testing.T) {
+	tests := []struct {
+		text   string
+		regex  bool
+		title  string
+		want   string
+		hit    bool
+		offset bool
+	}{
+		{"CHS", false, "Show.S01E02.CHS.1080p", "Show.S01E02..1080p", true, false},
+		{"CHT", false, "Show.S01E02.CHS.1080p", "Show.S01E02.CHS.1080p", false, false},
+		// 非正则时特殊字符按原文匹配
+		{"Spy.x.Family => 间谍过家家", false, "Spy.x.Family.S01E02", "间谍过家家.S01E02", true, false},
+		{"Spy.x.Family => 间谍过家家", false, "SpyAxBFamily.S01E02", "SpyAxBFamily.S01E02", false, false},
+		{`Spy\.x\.Family => 间谍过家家`, true, "Spy.x.Family.S01E02", "间谍过家家.S01E02", true, false},
+		{`(\w+)\.Remastered => $1`, true, "Show.Remastered.S01E02", "Show.S01E02", true, false},
+		// 集偏移保持位数，中文数字转为阿拉伯数字，结果小于 0 时为 0
+		{"第 <> 集 >> EP-12", false, "Show 第15集", "Show 第03集", false, true},
+		{"第 <> 集 >> EP*2", false, "Show 第五集", "Show 第10集", false, true},
+		{"第 <> 集 >> EP-20", false, "Show 第05集", "Show 第00集", false, true},
+		{"[ <> ]【 >> EP+12", false, "[Show][05]【1080p】", "[Show][17]【1080p】", false, true},
+		{"Show2 => Show && E <> . >> EP+10", false, "Show2.E05.1080p", "Show.E15.1080p", true, true},
+		{"Show2 => Show && E <> . >> EP+10", false, "Other.E05.1080p", "Other.E05.1080p", false, false},
+	}
+	for _, tt := range tests {
+		w, err := ParseWord(tt.text, tt.regex)
+		if err != nil {
+			t.Fatalf("ParseWord(%q) failed, %s", tt.text, err.Error())
+		}
+		p := newWordProcess()
+		p.Load([]*Word{w})
+		got, info, err := p.Process(tt.title)
+		if err != nil {
+			t.Errorf("%q on %q failed, %s", tt.text, tt.title, err.Error())
+			continue
+		}
+		hit := len(info.Replaced) > 0 || len(info.Ignored) > 0
+		if got != tt.want || hit != tt.hit || (len(info.Offset) > 0) != tt.offset {
+			t.Errorf("%q on %q = %q %+v, want %q", tt.text, tt.title, got, info, tt.want)
+		}
+	}
+}
+
+func TestWordProcessOrder(t *testing.T) {
+	var words []*Word
+	for _, text := range []string{"Show2 => Show", "Show => 剧集", "CHS"} {
+		w, err := ParseWord(text, false)
+		if err != nil {
+			t.Fatalf("ParseWord(%q) failed, %s", text, err.Error())
+		}
+		words = append(words, w)
+	}
+	p := newWordProcess()
+	p.Load(words)
+	got, info, err := p.Process("Show2.E05.CHS")
+	if err != nil || got != "剧集.E05." {
+		t.Errorf("Process = %q, %v", got, err)
+	}
+	if want := []string{"Show2 => Show", "Show => 剧集"}; !reflect.DeepEqual(info.Replaced, want) {
+		t.Errorf("Replaced = %q, want %q", info.Replaced, want)
+	}
+	if want := []string{"CHS"}; !reflect.DeepEqual(info.Ignored, want) {
+		t.Errorf("Ignored = %q, want %q", info.Ignored, want)
+	}
+	p.Load(nil)
+	if got, _, _ = p.Process("Show2.E05.CHS"); got != "Show2.E05.CHS" {
+		t.Errorf("Process after Load(nil) = %q", got)
+	}
+}
+
+func TestEvalOffset(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+		err  error
+	}{
+		{"EP+1", 11, nil},
+		{"ep-3", 7, nil},
+		{"(EP+2)*3", 36, nil},
+		{"EP-1-1", 8, nil},
+		{"-EP+20", 10, nil},
+		{"EP/3", 3, nil},
+		{"EP+", 0, ErrWordFormat},
+		{"EP)", 0, ErrWordFormat},
+		{"EP/0", 0, ErrWordFormat},
+	}
+	for _, tt := range tests {
+		got, err := evalOffset(tt.expr, 10)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("evalOffset(%q, 10) = %d, %v, want %d, %v", tt.expr, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestWordMatchTimeout(t *testing.T) {
+	w, err := ParseWord(`(a+)+$`, true)
+	if err != nil {
+		t.Fatalf("ParseWord failed, %s", err.Error())
+	}
+	p := newWordProcess()
+	p.Load([]*Word{w})
+	start := time.Now()
+	title, _, err := p.Process(strings.Repeat("a", 64) + "b")
+	if d := time.Since(start); d > 10*WordMatchTimeout {
+		t.Errorf("Process took %s", d)
+	}
+	if err == nil || title != strings.Repeat("a", 64)+"b" {
+		t.Errorf("Process = %q, %v, want timeout error", title, err)
+	}
+}
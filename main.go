@@ -5,6 +5,7 @@ import (
 	"github.com/mysll/toolkit"
 	"mediahub/internal/conf"
 	"mediahub/server"
+	"os"
 	"runtime/debug"
 )
 
@@ -13,6 +14,9 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "parse" {
+		os.Exit(parseCmd(os.Args[2:]))
+	}
 	flag.Parse()
 	debug.SetTraceback("single")
 	server.Start(conf.LoadOption(conf.WithDataPath("./data")))
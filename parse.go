@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mediahub/internal/conf"
+	"mediahub/internal/db"
+	"mediahub/internal/media"
+	"mediahub/server"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const parseUsage = `Usage: mediahub parse [options] [title ...]
+
+Parse release titles or file names and print the recognized meta.
+Titles are read from stdin, one per line, when none are given.
+
+Options:
+`
+
+// parseCmd mediahub parse 子命令
+func parseCmd(args []string) int {
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), parseUsage)
+		fs.PrintDefaults()
+	}
+	subtitle := fs.String("subtitle", "", "subtitle of the title")
+	isFile := fs.Bool("file", false, "parse titles as file names")
+	isPath := fs.Bool("path", false, "parse titles as file paths, merging name, year and season from parent folders; cannot be combined with -subtitle, -file, -trace or -bench")
+	anim := fs.Bool("anim", false, "force anime parser")
+	format := fs.String("format", "json", "output format, json or table")
+	dataPath := fs.String("data", "", "data path, load custom words from its database when set")
+	trace := fs.Bool("trace", false, "print how each token is consumed by the parse steps")
+	workers := fs.Int("workers", 0, "number of parse workers, defaults to the number of CPUs")
+	bench := fs.Int("bench", 0, "parse all titles the given number of rounds and print timing instead of meta")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "json" && *format != "table" {
+		fmt.Fprintf(os.Stderr, "unknown format %s\n", *format)
+		return 2
+	}
+	if *isPath {
+		// 路径模式按扩展名判断文件，只识别文件名本身，不支持副标题、跟踪和测速
+		conflict := ""
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "subtitle", "file", "trace", "bench":
+				if conflict == "" {
+					conflict = f.Name
+				}
+			}
+		})
+		if conflict != "" {
+			fmt.Fprintf(os.Stderr, "-path cannot be used with -%s\n", conflict)
+			return 2
+		}
+	}
+	if *dataPath != "" {
+		server.Load(conf.LoadOption(conf.WithDataPath(*dataPath)))
+		defer db.Close()
+	}
+
+	mediaType := media.MediaUnknown
+	if *anim {
+		mediaType = media.MediaAnim
+	}
+	titles := fs.Args()
+	if len(titles) == 0 {
+		var err error
+		if titles, err = readLines(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "read stdin failed, %s\n", err.Error())
+			return 1
+		}
+	}
+	if *bench > 0 {
+		benchParse(os.Stdout, titles, *subtitle, mediaType, *isFile, *workers, *bench)
+		return 0
+	}
+	metas := make([]*media.Meta, 0, len(titles))
+	traces := make([]*media.Trace, 0, len(titles))
+	switch {
+	case *isPath:
+		for _, title := range titles {
+			if meta := media.NewMetaPath(title, mediaType); meta != nil {
+				metas = append(metas, meta.GetMeta())
+				traces = append(traces, nil)
+			}
+		}
+	case *trace:
+		for _, title := range titles {
+			if meta, t := media.ExplainMeta(title, *subtitle, mediaType, *isFile); meta != nil {
+				metas = append(metas, meta.GetMeta())
+				traces = append(traces, t)
+			}
+		}
+	default:
+		for _, meta := range media.ParseMany(parseItems(titles, *subtitle, mediaType, *isFile), *workers) {
+			if meta != nil {
+				metas = append(metas, meta.GetMeta())
+				traces = append(traces, nil)
+			}
+		}
+	}
+	if *format == "table" {
+		printMetaTable(os.Stdout, metas)
+		if *trace {
+			for _, t := range traces {
+				fmt.Println()
+				fmt.Print(t.String())
+			}
+		}
+		return 0
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	for i, meta := range metas {
+		var v interface{} = meta
+		if *trace {
+			v = struct {
+				Meta  *media.Meta  `json:"meta"`
+				Trace *media.Trace `json:"trace"`
+			}{meta, traces[i]}
+		}
+		if err := enc.Encode(v); err != nil {
+			fmt.Fprintf(os.Stderr, "encode meta failed, %s\n", err.Error())
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseItems(titles []string, subtitle string, mediaType int, isFile bool) []media.ParseItem {
+	items := make([]media.ParseItem, len(titles))
+	for i, title := range titles {
+		items[i] = media.ParseItem{Title: title, Subtitle: subtitle, MediaType: mediaType, IsFile: isFile}
+	}
+	return items
+}
+
+// benchParse 重复识别 rounds 轮，输出单条耗时和吞吐量
+func benchParse(w io.Writer, titles []string, subtitle string, mediaType int, isFile bool, workers, rounds int) {
+	items := parseItems(titles, subtitle, mediaType, isFile)
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mallocs, bytes := ms.Mallocs, ms.TotalAlloc
+	start := time.Now()
+	for i := 0; i < rounds; i++ {
+		media.ParseMany(items, workers)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&ms)
+	n := uint64(len(items) * rounds)
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w, "titles %d, rounds %d, workers %d\n", len(items), rounds, workers)
+	fmt.Fprintf(w, "%d ns/op  %d B/op  %d allocs/op  %.0f titles/s\n",
+		elapsed.Nanoseconds()/int64(n), (ms.TotalAlloc-bytes)/n, (ms.Mallocs-mallocs)/n,
+		float64(n)/elapsed.Seconds())
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0, 16)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func printMetaTable(w io.Writer, metas []*media.Meta) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TITLE\tTYPE\tCN NAME\tEN NAME\tJP/KR NAME\tYEAR\tSEASON\tEPISODE\tPIX\tSOURCE\tVIDEO\tAUDIO\tGROUP")
+	for _, m := range metas {
+		year := ""
+		if m.Year != 0 {
+			year = strconv.Itoa(m.Year)
+		}
+		episode := m.GetEpisodeString()
+		if episode == "" {
+			episode = m.AirDate
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.OrgString, media.MediaTypeName(m.MediaType), m.CnName, m.EnName, m.JpName+m.KrName, year,
+			m.GetSeasonString(), episode, m.ResourcePix, m.ResourceType,
+			m.VideoEncode, m.AudioEncode, m.ReleaseGroup)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mediahub/internal/media"
+	"os"
+	"strings"
+	"testing"
+)
+
+// runParse 执行 parse 子命令，返回退出码和标准输出
+func runParse(t *testing.T, stdin string, args ...string) (int, string) {
+	t.Helper()
+	stdout, stderr, in := os.Stdout, os.Stderr, os.Stdin
+	defer func() { os.Stdout, os.Stderr, os.Stdin = stdout, stderr, in }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = inW.WriteString(stdin)
+	inW.Close()
+	devNull, _ := os.Open(os.DevNull)
+	defer devNull.Close()
+	os.Stdout, os.Stderr, os.Stdin = w, devNull, inR
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	code := parseCmd(args)
+	w.Close()
+	return code, <-out
+}
+
+func decodeMetas(t *testing.T, out string) []media.Meta {
+	t.Helper()
+	var metas []media.Meta
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var m media.Meta
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("decode %q failed, %s", out, err.Error())
+		}
+		metas = append(metas, m)
+	}
+	return metas
+}
+
+func TestParseCmd(t *testing.T) {
+	code, out := runParse(t, "", "Inception.2010.1080p.BluRay.x264-CMCT", "Breaking.Bad.S01E02.1080p")
+	if code != 0 {
+		t.Fatalf("parse exit %d", code)
+	}
+	metas := decodeMetas(t, out)
+	if len(metas) != 2 || metas[0].EnName != "Inception" || metas[0].Year != 2010 ||
+		metas[1].EnName != "Breaking Bad" || metas[1].BeginEpisode != 2 {
+		t.Errorf("parse output %s", out)
+	}
+
+	// 没有参数时从标准输入按行读取，忽略空行
+	code, out = runParse(t, "Inception.2010.1080p\n\n  Breaking.Bad.S01E02.1080p  \n")
+	if metas = decodeMetas(t, out); code != 0 || len(metas) != 2 {
+		t.Errorf("parse stdin exit %d, output %s", code, out)
+	}
+
+	code, out = runParse(t, "", "-format", "table", "Inception.2010.1080p")
+	if code != 0 || !strings.HasPrefix(out, "TITLE") || !strings.Contains(out, "Inception") {
+		t.Errorf("parse table exit %d, output %s", code, out)
+	}
+}
+
+func TestParseCmdArgs(t *testing.T) {
+	tests := [][]string{
+		{"-format", "xml", "x"},
+		{"-unknown", "x"},
+	}
+	for _, args := range tests {
+		if code, out := runParse(t, "", args...); code != 2 || out != "" {
+			t.Errorf("parse %q exit %d, output %q, want 2", args, code, out)
+		}
+	}
+}
+
+func TestPrintMetaTable(t *testing.T) {
+	var buf bytes.Buffer
+	m := media.NewMeta("Show.2023.01.14.1080p.HDTV", "", media.MediaUnknown, false).GetMeta()
+	printMetaTable(&buf, []*media.Meta{m})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "2023-01-14") {
+		t.Errorf("printMetaTable = %q", buf.String())
+	}
+}
+
+func TestParseCmdBench(t *testing.T) {
+	code, out := runParse(t, "", "-bench", "2", "Inception.2010.1080p")
+	if code != 0 || !strings.Contains(out, "ns/op") {
+		t.Errorf("parse bench exit %d, output %s", code, out)
+	}
+}
+
+func TestParseCmdTrace(t *testing.T) {
+	code, out := runParse(t, "", "-trace", "Inception.2010.1080p")
+	var traced struct {
+		Meta  media.Meta   `json:"meta"`
+		Trace *media.Trace `json:"trace"`
+	}
+	if err := json.Unmarshal([]byte(out), &traced); code != 0 || err != nil || traced.Trace == nil || traced.Meta.Year != 2010 {
+		t.Errorf("parse trace exit %d, output %s", code, out)
+	}
+}
+
+func TestParseCmdPath(t *testing.T) {
+	code, out := runParse(t, "", "-path", "/media/Breaking Bad (2008)/Season 2/S02E03.mkv")
+	if metas := decodeMetas(t, out); code != 0 || len(metas) != 1 || metas[0].EnName != "Breaking Bad" ||
+		metas[0].BeginSeason != 2 || metas[0].BeginEpisode != 3 {
+		t.Errorf("parse path exit %d, output %s", code, out)
+	}
+
+	// -path 不能与只对标题生效的参数同时使用
+	tests := [][]string{
+		{"-path", "-subtitle", "sub", "x"},
+		{"-path", "-file", "x"},
+		{"-path", "-trace", "x"},
+		{"-path", "-bench", "1", "x"},
+	}
+	for _, args := range tests {
+		if code, out := runParse(t, "", args...); code != 2 || out != "" {
+			t.Errorf("parse %q exit %d, output %q, want 2", args, code, out)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	stdlog "log"
 	"mediahub/internal/conf"
 	"mediahub/internal/db"
+	"mediahub/internal/media"
 	"os"
 	"path/filepath"
 	"strings"
@@ -73,10 +74,95 @@ func initDb() {
 	log.Infof("init db")
 }
 
+func initMedia() {
+	c := conf.GetConfig().Media
+	if len(c.ReleaseGroups) > 0 {
+		if err := media.AddReleaseGroups("custom", c.ReleaseGroups...); err != nil {
+			log.Errorf("add release groups failed, error %s", err.Error())
+		}
+	}
+	for _, s := range c.CustomSteps {
+		if err := media.RegisterRegexStep(s); err != nil {
+			log.Errorf("register parse step %s failed, error %s", s.Name, err.Error())
+		}
+	}
+	if len(c.ParseSteps) > 0 {
+		if err := media.SetStepOrder(c.ParseSteps); err != nil {
+			log.Errorf("set parse steps failed, error %s", err.Error())
+		}
+	}
+	log.Infof("parse steps: %s", strings.Join(media.StepOrder(), ","))
+	media.SetQualityScore(c.QualityScore)
+	if c.CategoryFile != "" {
+		file := c.CategoryFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(conf.GetOptions().DataPath, file)
+		}
+		rules, err := media.LoadCategoryRules(file)
+		if err == nil {
+			err = media.SetCategoryRules(rules)
+		}
+		if err != nil {
+			log.Errorf("load category rules failed, error %s", err.Error())
+		}
+	}
+	providers := newProviders(c.Providers)
+	if len(providers) > 0 {
+		media.InitMedia(media.NewMedia(providers...))
+	} else {
+		log.Warnf("no metadata provider configured, media recognition disabled")
+	}
+	log.Infof("init media")
+}
+
+// newProviders 按优先级创建已配置的元数据来源
+func newProviders(names []string) []media.Provider {
+	if len(names) == 0 {
+		names = media.DefaultProviders
+	}
+	c := conf.GetConfig()
+	providers := make([]media.Provider, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case media.ProviderTmdb:
+			if c.Tmdb.ApiKey == "" {
+				continue
+			}
+			tmdb := media.NewTmdb(c.Tmdb.ApiKey, c.Tmdb.Language, c.Tmdb.Proxy)
+			tmdb.SetStore(db.TmdbStore{})
+			tmdb.SetTTL(time.Duration(c.Tmdb.SearchTTL)*time.Hour, time.Duration(c.Tmdb.DetailTTL)*time.Hour)
+			tmdb.SetOffline(c.Tmdb.Offline)
+			tmdb.SetRateLimit(c.Tmdb.RateLimit, c.Tmdb.RateBurst)
+			providers = append(providers, tmdb)
+		case media.ProviderDouban:
+			if !c.Douban.Enable {
+				continue
+			}
+			providers = append(providers, media.NewDouban(c.Douban.Proxy))
+		case media.ProviderTvdb:
+			if c.Tvdb.ApiKey == "" {
+				continue
+			}
+			providers = append(providers, media.NewTvdb(c.Tvdb.ApiKey, c.Tvdb.Pin, c.Tmdb.Language, c.Tvdb.Proxy))
+		default:
+			log.Errorf("unknown metadata provider %s", name)
+			continue
+		}
+		log.Infof("metadata provider: %s", name)
+	}
+	return providers
+}
+
 func preload(options *conf.Options) {
 	log.Infof("MediaHub version: %s", conf.AppVersion)
 	initConfig(options)
 	initDb()
+	initMedia()
+}
+
+// Load 只加载配置和数据库，不启动服务，供命令行工具使用
+func Load(option *conf.Options) {
+	preload(option)
 }
 
 func Start(option *conf.Options) {
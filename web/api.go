@@ -1 +1,30 @@
 package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type Resp struct {
+	Code int         `json:"code"`
+	Msg  string      `json:"msg"`
+	Data interface{} `json:"data"`
+}
+
+func success(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, Resp{Code: 0, Msg: "success", Data: data})
+}
+
+func fail(c *gin.Context, code int, err error) {
+	c.JSON(code, Resp{Code: code, Msg: err.Error()})
+}
+
+func paramId(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return 0, false
+	}
+	return uint(id), true
+}
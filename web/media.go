@@ -0,0 +1,291 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"mediahub/internal/media"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+func initMedia(g *gin.RouterGroup) {
+	m := g.Group("/media")
+	m.POST("/parse", parseTitle)
+	m.POST("/parse/batch", parseTitles)
+	m.POST("/compare", compareQuality)
+	m.POST("/recognize", recognizeMedia)
+	m.POST("/cache/invalidate", invalidateCache)
+	m.GET("/cache/stats", cacheStats)
+	m.GET("/tv/:id/seasons", getSeasons)
+	m.GET("/tv/:id/season/:season", getSeason)
+	m.GET("/tv/:id/season/:season/episode/:episode", getEpisode)
+}
+
+type parseReq struct {
+	Title    string `json:"title" binding:"required"`
+	Subtitle string `json:"subtitle"`
+	IsFile   bool   `json:"is_file"`
+	Path     bool   `json:"path"` // title 为文件路径，合并上级目录信息
+	Anim     bool   `json:"anim"`
+	Trace    bool   `json:"trace"`
+}
+
+type parseResp struct {
+	Meta    *media.Meta   `json:"meta"`
+	Quality media.Quality `json:"quality"`
+	Score   int           `json:"score"`
+	Trace   *media.Trace  `json:"trace,omitempty"`
+}
+
+// parseTitle 识别标题，trace 为 true 时返回解析过程
+func parseTitle(c *gin.Context) {
+	req := new(parseReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	mediaType := media.MediaUnknown
+	if req.Anim {
+		mediaType = media.MediaAnim
+	}
+	resp := parseResp{}
+	var meta media.MetaInfo
+	if req.Path {
+		meta = media.NewMetaPath(req.Title, mediaType)
+	} else if req.Trace {
+		meta, resp.Trace = media.ExplainMeta(req.Title, req.Subtitle, mediaType, req.IsFile)
+	} else {
+		meta = media.NewMeta(req.Title, req.Subtitle, mediaType, req.IsFile)
+	}
+	if meta == nil {
+		fail(c, http.StatusNotFound, media.ErrMediaNotFound)
+		return
+	}
+	resp.Meta = meta.GetMeta()
+	resp.Quality = resp.Meta.GetQuality()
+	resp.Score = media.ScoreQuality(resp.Quality)
+	success(c, resp)
+}
+
+// recognizeMedia 识别标题并从 TMDB 补充媒体信息
+func recognizeMedia(c *gin.Context) {
+	req := new(parseReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	m := media.GetMedia()
+	if m == nil {
+		fail(c, http.StatusServiceUnavailable, media.ErrNoProvider)
+		return
+	}
+	mediaType := media.MediaUnknown
+	if req.Anim {
+		mediaType = media.MediaAnim
+	}
+	var meta media.MetaInfo
+	if req.Path {
+		meta = media.NewMetaPath(req.Title, mediaType)
+	} else {
+		meta = media.NewMeta(req.Title, req.Subtitle, mediaType, req.IsFile)
+	}
+	if meta == nil {
+		fail(c, http.StatusNotFound, media.ErrMediaNotFound)
+		return
+	}
+	if err := m.Recognize(meta); err != nil {
+		if errors.Is(err, media.ErrMediaNotFound) {
+			fail(c, http.StatusNotFound, err)
+		} else {
+			fail(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	success(c, meta.GetMeta())
+}
+
+type invalidateReq struct {
+	Kind string `json:"kind"` // search、movie、tv、season、episode，为空时删除全部
+	Id   string `json:"id"`   // 详情为 TMDB ID，季为 剧集ID:季，搜索为名称，为空时删除该类型全部
+}
+
+// invalidateCache 删除 TMDB 缓存
+func invalidateCache(c *gin.Context) {
+	req := new(invalidateReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	t := tmdbOrFail(c)
+	if t == nil {
+		return
+	}
+	if err := t.Invalidate(req.Kind, req.Id); err != nil {
+		if errors.Is(err, media.ErrUnknownTmdbCache) {
+			fail(c, http.StatusBadRequest, err)
+		} else {
+			fail(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	success(c, nil)
+}
+
+// cacheStats TMDB 缓存命中统计
+func cacheStats(c *gin.Context) {
+	t := tmdbOrFail(c)
+	if t == nil {
+		return
+	}
+	success(c, t.Stats())
+}
+
+// tmdbOrFail 未配置 TMDB 时返回错误
+func tmdbOrFail(c *gin.Context) *media.Tmdb {
+	m := media.GetMedia()
+	if m == nil || m.Tmdb() == nil {
+		fail(c, http.StatusServiceUnavailable, media.ErrTmdbNotConfigured)
+		return nil
+	}
+	return m.Tmdb()
+}
+
+func paramInt(c *gin.Context, name string) (int, bool) {
+	n, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return 0, false
+	}
+	return n, true
+}
+
+func getSeasons(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	t := tmdbOrFail(c)
+	if t == nil {
+		return
+	}
+	seasons, err := t.GetSeasons(id)
+	if err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, seasons)
+}
+
+func getSeason(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	season, ok := paramInt(c, "season")
+	if !ok {
+		return
+	}
+	t := tmdbOrFail(c)
+	if t == nil {
+		return
+	}
+	detail, err := t.GetSeasonDetail(id, season)
+	if err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, detail)
+}
+
+func getEpisode(c *gin.Context) {
+	id, ok := paramInt(c, "id")
+	if !ok {
+		return
+	}
+	season, ok := paramInt(c, "season")
+	if !ok {
+		return
+	}
+	episode, ok := paramInt(c, "episode")
+	if !ok {
+		return
+	}
+	t := tmdbOrFail(c)
+	if t == nil {
+		return
+	}
+	detail, err := t.GetEpisodeDetail(id, season, episode)
+	if err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, detail)
+}
+
+type parseBatchReq struct {
+	Titles []string `json:"titles" binding:"required"`
+	IsFile bool     `json:"is_file"`
+	Anim   bool     `json:"anim"`
+}
+
+// parseTitles 批量识别标题，结果与输入顺序一致
+func parseTitles(c *gin.Context) {
+	req := new(parseBatchReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	mediaType := media.MediaUnknown
+	if req.Anim {
+		mediaType = media.MediaAnim
+	}
+	items := make([]media.ParseItem, len(req.Titles))
+	for i, title := range req.Titles {
+		items[i] = media.ParseItem{Title: title, MediaType: mediaType, IsFile: req.IsFile}
+	}
+	metas := make([]*media.Meta, len(items))
+	for i, meta := range media.ParseMany(items, 0) {
+		if meta != nil {
+			metas[i] = meta.GetMeta()
+		}
+	}
+	success(c, metas)
+}
+
+type compareReq struct {
+	Titles []string `json:"titles" binding:"required"`
+	Anim   bool     `json:"anim"`
+}
+
+type compareItem struct {
+	Title   string        `json:"title"`
+	Quality media.Quality `json:"quality"`
+	Score   int           `json:"score"`
+}
+
+// compareQuality 按质量分从高到低排列同一媒体的多个资源，无法识别的标题不参与排序
+func compareQuality(c *gin.Context) {
+	req := new(compareReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	mediaType := media.MediaUnknown
+	if req.Anim {
+		mediaType = media.MediaAnim
+	}
+	items := make([]compareItem, 0, len(req.Titles))
+	for _, title := range req.Titles {
+		meta := media.NewMeta(title, "", mediaType, false)
+		if meta == nil {
+			continue
+		}
+		q := meta.GetMeta().GetQuality()
+		items = append(items, compareItem{Title: title, Quality: q, Score: media.ScoreQuality(q)})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Score > items[j].Score
+	})
+	success(c, items)
+}
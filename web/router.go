@@ -13,6 +13,8 @@ func Init(e *gin.Engine) {
 	g.Any("/ping", func(c *gin.Context) {
 		c.String(200, "pong")
 	})
+	initWord(g)
+	initMedia(g)
 }
 
 func Cors(e *gin.Engine) {
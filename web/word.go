@@ -0,0 +1,120 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"mediahub/internal/db"
+	"mediahub/internal/model"
+	"mediahub/internal/utils"
+	"net/http"
+)
+
+func initWord(g *gin.RouterGroup) {
+	w := g.Group("/words")
+	w.GET("", listWords)
+	w.POST("", addWord)
+	w.PUT("/:id", updateWord)
+	w.DELETE("/:id", deleteWord)
+	w.POST("/reload", reloadWords)
+	w.POST("/test", testWord)
+}
+
+func listWords(c *gin.Context) {
+	words, err := db.GetWords()
+	if err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, words)
+}
+
+func saveWord(c *gin.Context, word *model.CustomWord) {
+	if _, err := utils.ParseWord(word.Word, word.Regex); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := db.SaveWord(word); err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := db.ReloadWords(); err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, word)
+}
+
+func addWord(c *gin.Context) {
+	word := new(model.CustomWord)
+	if err := c.ShouldBindJSON(word); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	word.ID = 0
+	saveWord(c, word)
+}
+
+func updateWord(c *gin.Context) {
+	id, ok := paramId(c)
+	if !ok {
+		return
+	}
+	word, err := db.GetWord(id)
+	if err != nil {
+		fail(c, http.StatusNotFound, err)
+		return
+	}
+	if err = c.ShouldBindJSON(word); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	word.ID = id
+	saveWord(c, word)
+}
+
+func deleteWord(c *gin.Context) {
+	id, ok := paramId(c)
+	if !ok {
+		return
+	}
+	if err := db.DeleteWord(id); err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := db.ReloadWords(); err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, nil)
+}
+
+func reloadWords(c *gin.Context) {
+	if err := db.ReloadWords(); err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, nil)
+}
+
+type testWordReq struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type testWordResp struct {
+	Title string            `json:"title"`
+	Info  utils.ProcessInfo `json:"info"`
+}
+
+// testWord 使用当前识别词处理标题
+func testWord(c *gin.Context) {
+	req := new(testWordReq)
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, http.StatusBadRequest, err)
+		return
+	}
+	title, info, err := utils.ProcessTitle(req.Title)
+	if err != nil {
+		fail(c, http.StatusInternalServerError, err)
+		return
+	}
+	success(c, testWordResp{Title: title, Info: info})
+}